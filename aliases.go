@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func defaultAliasesPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pokedex_aliases")
+}
+
+// loadAliases reads "alias = command" lines from path. Blank lines and lines
+// starting with # are ignored. A missing file yields no aliases.
+func loadAliases(path string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	if path == "" {
+		return aliases, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return aliases, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		alias, target, ok := strings.Cut(line, "=")
+		alias, target = strings.TrimSpace(alias), strings.TrimSpace(target)
+		if !ok || alias == "" || target == "" {
+			return nil, fmt.Errorf("%s:%d: expected \"alias = command\"", path, lineNo)
+		}
+		aliases[alias] = target
+	}
+	return aliases, scanner.Err()
+}
+
+// registerAliases adds each alias to commands, pointing at its target's
+// callback. Aliases that shadow a built-in or name an unknown command are
+// rejected.
+func registerAliases(aliases map[string]string) []error {
+	errs := make([]error, 0)
+	for alias, target := range aliases {
+		if _, exists := commands[alias]; exists {
+			errs = append(errs, fmt.Errorf("alias %q collides with a built-in command", alias))
+			continue
+		}
+		cmd, ok := commands[target]
+		if !ok || cmd.name != target {
+			errs = append(errs, fmt.Errorf("alias %q points to unknown command %q", alias, target))
+			continue
+		}
+		commands[alias] = cmd
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeAliases(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "aliases")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAliases(t *testing.T) {
+	path := writeAliases(t, "# my aliases\n\nsides = dicesides\n  back=mapb  \n")
+	aliases, err := loadAliases(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"sides": "dicesides", "back": "mapb"}
+	if !reflect.DeepEqual(aliases, want) {
+		t.Errorf("loadAliases = %v, want %v", aliases, want)
+	}
+
+	if aliases, err := loadAliases(filepath.Join(t.TempDir(), "missing")); err != nil || len(aliases) != 0 {
+		t.Errorf("missing file gave %v, %v, want no aliases", aliases, err)
+	}
+	if _, err := loadAliases(writeAliases(t, "sides dicesides\n")); err == nil {
+		t.Error("accepted a line without '='")
+	}
+}
+
+func TestAliasDispatches(t *testing.T) {
+	setupTest(t)
+	aliases, err := loadAliases(writeAliases(t, "sides = dicesides\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := registerAliases(aliases); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	t.Cleanup(func() { delete(commands, "sides") })
+
+	captureOutput(t, func() {
+		if err := dispatch("sides 12"); err != nil {
+			t.Errorf("dispatch: %v", err)
+		}
+	})
+	if diceSides != 12 {
+		t.Errorf("diceSides = %d, want 12", diceSides)
+	}
+}
+
+func TestRegisterAliasesRejectsBadAliases(t *testing.T) {
+	setupTest(t)
+	mapDescription := commands["map"].description
+	errs := registerAliases(map[string]string{
+		"map":   "help",
+		"fetch": "nope",
+	})
+	t.Cleanup(func() { delete(commands, "fetch") })
+	if len(errs) != 2 {
+		t.Errorf("got errors %v, want one per alias", errs)
+	}
+	if commands["map"].name != "map" || commands["map"].description != mapDescription {
+		t.Error("an alias replaced the built-in map command")
+	}
+	if _, ok := commands["fetch"]; ok {
+		t.Error("registered an alias to an unknown command")
+	}
+}
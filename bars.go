@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// maxBaseStat is the highest base stat a pokemon can have.
+	maxBaseStat = 255
+	barWidth    = 20
+)
+
+// renderBar draws value as a fixed-width bar scaled to maxBaseStat,
+// e.g. "[#######-------------]".
+func renderBar(value int) string {
+	filled := value * barWidth / maxBaseStat
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > barWidth {
+		filled = barWidth
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", barWidth-filled) + "]"
+}
+
+// ANSI color codes used by colorize.
+const (
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorGreen  = "\033[32m"
+	colorReset  = "\033[0m"
+)
+
+// noColor turns colors off, see Config.NoColor and https://no-color.org.
+var noColor bool
+
+// colorEnabled reports whether output may be colored: stdout must be a
+// terminal and NO_COLOR must be unset.
+func colorEnabled() bool {
+	return !noColor && isTerminal(os.Stdout)
+}
+
+// colorize wraps s in color when colors are enabled.
+func colorize(s, color string) string {
+	if !colorEnabled() {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// statColor picks the color for a base stat: red below 50, yellow from 50
+// to 89 and green from 90.
+func statColor(value int) string {
+	switch {
+	case value < 50:
+		return colorRed
+	case value < 90:
+		return colorYellow
+	default:
+		return colorGreen
+	}
+}
+
+// statAbbreviations maps pokeapi stat names to their usual short forms.
+var statAbbreviations = map[string]string{
+	"hp":              "HP",
+	"attack":          "Atk",
+	"defense":         "Def",
+	"special-attack":  "SpA",
+	"special-defense": "SpD",
+	"speed":           "Spe",
+}
+
+// abbreviateStat returns the short form of a stat name, e.g. "SpA" for
+// "special-attack". Unknown names are returned unchanged.
+func abbreviateStat(name string) string {
+	if short, ok := statAbbreviations[name]; ok {
+		return short
+	}
+	return name
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBar(t *testing.T) {
+	tests := []struct {
+		value  int
+		filled int
+	}{
+		{-5, 0},
+		{0, 0},
+		{12, 0},
+		{13, 1},
+		{90, 7},
+		{128, 10},
+		{255, 20},
+		{300, 20},
+	}
+	for _, tt := range tests {
+		bar := renderBar(tt.value)
+		if len(bar) != barWidth+2 || bar[0] != '[' || bar[len(bar)-1] != ']' {
+			t.Errorf("renderBar(%d) = %q, want %d cells between brackets", tt.value, bar, barWidth)
+			continue
+		}
+		if got := strings.Count(bar, "#"); got != tt.filled {
+			t.Errorf("renderBar(%d) = %q, filled %d cells, want %d", tt.value, bar, got, tt.filled)
+		}
+	}
+}
+
+func TestStatColor(t *testing.T) {
+	tests := []struct {
+		value int
+		want  string
+	}{
+		{0, colorRed},
+		{49, colorRed},
+		{50, colorYellow},
+		{89, colorYellow},
+		{90, colorGreen},
+		{255, colorGreen},
+	}
+	for _, tt := range tests {
+		if got := statColor(tt.value); got != tt.want {
+			t.Errorf("statColor(%d) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	setupTest(t)
+	for _, env := range []string{"1", "true", "anything"} {
+		cfg, err := LoadConfig(func(name string) string {
+			if name == "NO_COLOR" {
+				return env
+			}
+			return ""
+		})
+		if err != nil || !cfg.NoColor {
+			t.Errorf("NO_COLOR=%s gave NoColor %v, %v", env, cfg.NoColor, err)
+		}
+	}
+	if cfg, _ := LoadConfig(func(string) string { return "" }); cfg.NoColor {
+		t.Error("NoColor set without NO_COLOR")
+	}
+
+	noColor = true
+	if got := colorize("90", colorGreen); got != "90" {
+		t.Errorf("colorize with colors off = %q, want the plain text", got)
+	}
+}
+
+func TestAbbreviateStat(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"hp", "HP"},
+		{"attack", "Atk"},
+		{"defense", "Def"},
+		{"special-attack", "SpA"},
+		{"special-defense", "SpD"},
+		{"speed", "Spe"},
+		{"accuracy", "accuracy"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := abbreviateStat(tt.name); got != tt.want {
+			t.Errorf("abbreviateStat(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInspectStatNames(t *testing.T) {
+	setupTest(t)
+	noColor = true
+	catchPokemon(t, pokemonWithStats(t, 1, "bulbasaur", "hp", 45, "special-attack", 65))
+	out := captureOutput(t, func() { commandInspect("bulbasaur") })
+	if !strings.Contains(out, "  -HP: 45\n  -SpA: 65\n") {
+		t.Errorf("inspect printed:\n%s", out)
+	}
+	out = captureOutput(t, func() { commandInspect("bulbasaur", "--long") })
+	if !strings.Contains(out, "  -hp: 45\n  -special-attack: 65\n") {
+		t.Errorf("inspect --long printed:\n%s", out)
+	}
+}
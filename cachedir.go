@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// resolveCacheDir picks the directory the disk cache lives in: override when
+// set, else <user cache dir>/pokedex, else <temp dir>/pokedex. The directory
+// is created if missing. It returns "" when none can be used, which disables
+// the disk cache.
+func resolveCacheDir(override string, userCacheDir func() (string, error)) string {
+	candidates := make([]string, 0, 2)
+	if override != "" {
+		candidates = append(candidates, override)
+	} else if dir, err := userCacheDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "pokedex"))
+	}
+	candidates = append(candidates, filepath.Join(os.TempDir(), "pokedex"))
+
+	for _, dir := range candidates {
+		if err := os.MkdirAll(dir, 0755); err == nil {
+			return dir
+		}
+	}
+	return ""
+}
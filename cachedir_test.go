@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveCacheDir(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	userCache := t.TempDir()
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	userCacheDir := func() (string, error) { return userCache, nil }
+	noUserCacheDir := func() (string, error) { return "", errors.New("no home") }
+
+	tests := []struct {
+		name         string
+		override     string
+		userCacheDir func() (string, error)
+		want         string
+	}{
+		{"override", filepath.Join(userCache, "custom"), userCacheDir, filepath.Join(userCache, "custom")},
+		{"default", "", userCacheDir, filepath.Join(userCache, "pokedex")},
+		{"no user cache dir", "", noUserCacheDir, filepath.Join(tmp, "pokedex")},
+		{"unusable override", filepath.Join(blocked, "cache"), userCacheDir, filepath.Join(tmp, "pokedex")},
+		{"unusable default", "", func() (string, error) { return blocked, nil }, filepath.Join(tmp, "pokedex")},
+	}
+	for _, tt := range tests {
+		got := resolveCacheDir(tt.override, tt.userCacheDir)
+		if got != tt.want {
+			t.Errorf("%s: resolveCacheDir = %q, want %q", tt.name, got, tt.want)
+			continue
+		}
+		if info, err := os.Stat(got); err != nil || !info.IsDir() {
+			t.Errorf("%s: %s was not created", tt.name, got)
+		}
+	}
+}
+
+func TestResolveCacheDirNothingUsable(t *testing.T) {
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TMPDIR", blocked)
+	if got := resolveCacheDir(filepath.Join(blocked, "cache"), os.UserCacheDir); got != "" {
+		t.Errorf("resolveCacheDir = %q, want the disk cache disabled", got)
+	}
+}
+
+func TestOpenDiskCache(t *testing.T) {
+	setupTest(t)
+	dir := t.TempDir()
+	url := primaryBaseURL + "/pokemon/25"
+	cacheDir = dir
+	pCache.Add(url, []byte(pokemonJSON(25, "pikachu", 112, "electric")))
+	flushCache()
+
+	setupTest(t)
+	openDiskCache(dir)
+	if cacheDir != dir || !pCache.Contains(url) {
+		t.Errorf("openDiskCache(%q) set cacheDir %q, cached pikachu: %v", dir, cacheDir, pCache.Contains(url))
+	}
+}
+
+func TestCommandValidateCache(t *testing.T) {
+	setupTest(t)
+	out := captureOutput(t, func() { commandValidateCache() })
+	if out != "The disk cache is disabled\n" {
+		t.Errorf("validatecache without a disk cache printed %q", out)
+	}
+
+	cacheDir = t.TempDir()
+	pCache.Add(primaryBaseURL+"/pokemon/25", []byte(pokemonJSON(25, "pikachu", 112, "electric")))
+	flushCache()
+	if err := os.WriteFile(filepath.Join(cacheDir, "broken.json"), []byte(`{"key":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out = captureOutput(t, func() {
+		if err := commandValidateCache(); err != nil {
+			t.Errorf("validatecache: %v", err)
+		}
+	})
+	for _, want := range []string{"1 valid cache files", "1 corrupt cache files:", "  - broken.json", "validatecache --remove"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	captureOutput(t, func() { commandValidateCache("--remove") })
+	if _, err := os.Stat(filepath.Join(cacheDir, "broken.json")); !os.IsNotExist(err) {
+		t.Errorf("validatecache --remove kept the corrupt file: %v", err)
+	}
+}
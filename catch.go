@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ablanchetMD/pokedex/pokecache"
+)
+
+// catchThreshold is the highest dice * base experience that still catches.
+const catchThreshold = 400
+
+const (
+	// notFoundRetries is how many times catch --retry404 retries a 404.
+	notFoundRetries = 2
+	// notFoundDelay is the pause between those retries.
+	notFoundDelay = 2 * time.Second
+)
+
+// fetchPokemonRetrying fetches a pokemon like fetchValid, but retries when
+// pokeapi answers 404, which briefly happens for newly added pokemon.
+func fetchPokemonRetrying(url string) ([]byte, error) {
+	body, err := fetchValid(url, validatePokemon)
+	for retry := 1; retry <= notFoundRetries && isNotFound(err); retry++ {
+		fmt.Printf("Not found, retrying in %s (%d/%d)...\n", notFoundDelay, retry, notFoundRetries)
+		sleep(notFoundDelay)
+		body, err = fetchValid(url, validatePokemon)
+	}
+	return body, err
+}
+
+// diceSides is the number of faces of the catch die, rolled as
+// 0..diceSides-1. More sides make catching harder.
+var diceSides = 10
+
+// pokeball improves the odds of a catch by dividing the pokemon's base
+// experience by multiplier. A guaranteed ball always catches.
+type pokeball struct {
+	name       string
+	multiplier float64
+	guaranteed bool
+}
+
+var pokeballs = map[string]pokeball{
+	"poke":   {name: "Poke Ball", multiplier: 1},
+	"great":  {name: "Great Ball", multiplier: 1.5},
+	"ultra":  {name: "Ultra Ball", multiplier: 2},
+	"master": {name: "Master Ball", guaranteed: true},
+}
+
+// lookupBall returns the ball called name, or a regular Poke Ball when name
+// is empty.
+func lookupBall(name string) (pokeball, error) {
+	if name == "" {
+		name = "poke"
+	}
+	ball, ok := pokeballs[strings.ToLower(name)]
+	if !ok {
+		return pokeball{}, fmt.Errorf("unknown ball %q, use great, ultra or master", name)
+	}
+	return ball, nil
+}
+
+// effectiveExperience is the base experience a catch roll is compared
+// against once ball is applied.
+func effectiveExperience(baseExperience int, ball pokeball) int {
+	if ball.guaranteed || baseExperience <= 0 {
+		return 0
+	}
+	return int(float64(baseExperience) / ball.multiplier)
+}
+
+// catchSucceeds reports whether a roll of dice catches a pokemon with the
+// given base experience: the catch succeeds when dice * baseExperience stays
+// within catchThreshold. The product is compared by division so huge base
+// experience values cannot overflow, and negative ones count as zero.
+func catchSucceeds(dice, baseExperience int) bool {
+	if baseExperience <= 0 || dice <= 0 {
+		return true
+	}
+	return dice <= catchThreshold/baseExperience
+}
+
+// CalculateCatchChance returns the probability that a catch roll with ball
+// succeeds against a pokemon with the given base experience. A roll of 0
+// always succeeds, so the chance lies in [1/diceSides, 1]. The winning rolls
+// are counted without enumerating them, as catchSucceeds accepts exactly the
+// rolls 0 through catchThreshold/experience.
+func CalculateCatchChance(baseExperience int, ball pokeball) float64 {
+	experience := effectiveExperience(baseExperience, ball)
+	successes := diceSides
+	if experience > 0 {
+		successes = min(diceSides, catchThreshold/experience+1)
+	}
+	return clamp(float64(successes)/float64(diceSides), 0, 1)
+}
+
+func clamp(value, low, high float64) float64 {
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
+
+func commandDiceSides(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Dice sides:", diceSides)
+		return nil
+	}
+	n, err := strconv.Atoi(params[0])
+	if err != nil || n < 1 {
+		fmt.Println("Please provide a positive number")
+		return errors.New("invalid dice sides")
+	}
+	diceSides = n
+	fmt.Println("Dice sides set to", diceSides)
+	return nil
+}
+
+// catchDelay is the pause between each dot of the catch animation. The
+// animation only plays in an interactive session; zero turns it off.
+var catchDelay = 400 * time.Millisecond
+
+// sleep pauses the catch animation and the 404 retries; tests can swap it
+// out.
+var sleep = time.Sleep
+
+// catchAnimation prints a few dots, pausing catchDelay before each one.
+func catchAnimation() {
+	if catchDelay <= 0 || !interactive() {
+		return
+	}
+	for i := 0; i < 3; i++ {
+		sleep(catchDelay)
+		fmt.Print(".")
+	}
+	fmt.Println()
+}
+
+func commandCatchDelay(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Catch delay:", catchDelay)
+		return nil
+	}
+	if params[0] == "off" {
+		catchDelay = 0
+		fmt.Println("Catch animation turned off")
+		return nil
+	}
+	d, err := time.ParseDuration(params[0])
+	if err != nil || d < 0 {
+		fmt.Println("Please provide a duration like 300ms, or off")
+		return errors.New("invalid catch delay")
+	}
+	catchDelay = d
+	fmt.Println("Catch delay set to", catchDelay)
+	return nil
+}
+
+// catchCooldown stops the same pokemon from being thrown at again too soon.
+type catchCooldown struct {
+	duration time.Duration
+	last     map[string]time.Time
+	clock    pokecache.Clock
+}
+
+var pCooldown = &catchCooldown{
+	last:  make(map[string]time.Time),
+	clock: pokecache.RealClock,
+}
+
+// Remaining returns how long until name can be thrown at again.
+func (c *catchCooldown) Remaining(name string) time.Duration {
+	last, ok := c.last[name]
+	if !ok || c.duration <= 0 {
+		return 0
+	}
+	remaining := c.duration - c.clock.Now().Sub(last)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Mark records an attempt at name.
+func (c *catchCooldown) Mark(name string) {
+	c.last[name] = c.clock.Now()
+}
+
+func commandCooldown(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Catch cooldown:", pCooldown.duration)
+		return nil
+	}
+	seconds, err := strconv.Atoi(params[0])
+	if err != nil || seconds < 0 {
+		fmt.Println("Please provide a number of seconds (0 to disable)")
+		return errors.New("invalid cooldown")
+	}
+	pCooldown.duration = time.Duration(seconds) * time.Second
+	fmt.Println("Catch cooldown set to", pCooldown.duration)
+	return nil
+}
+
+func commandCatchRate(params ...string) error {
+	ballName, params := takeFlagValue(params, "ball")
+	ball, err := lookupBall(ballName)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	if len(params) < 1 {
+		fmt.Println("Please provide a Pokemon name")
+		return errors.New("no Pokemon name provided")
+	}
+	pokemon, err := fetchPokemon(params[0])
+	if err != nil {
+		return err
+	}
+	ball = currentWeather.apply(ball, pokemon)
+	fmt.Printf("Chance to catch %s (%s): %.0f%%\n", pokemon.Name, ball.name, CalculateCatchChance(pokemon.BaseExperience, ball)*100)
+	return nil
+}
+
+// fetchPokemon looks up the pokemon called name, through the cache.
+func fetchPokemon(name string) (Pokemon, error) {
+	var pokemon Pokemon
+	body, err := fetchValid(pokemonPrefix+name, validatePokemon)
+	if err != nil {
+		return pokemon, err
+	}
+	err = json.Unmarshal(body, &pokemon)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+	}
+	return pokemon, err
+}
+
+// maxSimulations caps how many rolls catchsim makes.
+const maxSimulations = 1000000
+
+// simulateCatches rolls the catch die n times with r and returns how many
+// rolls would have caught a pokemon with the given base experience.
+func simulateCatches(r *rand.Rand, n, baseExperience int, ball pokeball) int {
+	experience := effectiveExperience(baseExperience, ball)
+	successes := 0
+	for i := 0; i < n; i++ {
+		if catchSucceeds(r.Intn(diceSides), experience) {
+			successes++
+		}
+	}
+	return successes
+}
+
+func commandCatchSim(params ...string) error {
+	ballName, params := takeFlagValue(params, "ball")
+	ball, err := lookupBall(ballName)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	if len(params) < 2 {
+		fmt.Println("Usage: catchsim <pokemon> <n>")
+		return errors.New("missing arguments")
+	}
+	n, err := strconv.Atoi(params[1])
+	if err != nil || n < 1 || n > maxSimulations {
+		fmt.Printf("Please provide a number of rolls between 1 and %d\n", maxSimulations)
+		return errors.New("invalid number of rolls")
+	}
+	pokemon, err := fetchPokemon(params[0])
+	if err != nil {
+		return err
+	}
+	ball = currentWeather.apply(ball, pokemon)
+	successes := simulateCatches(rng, n, pokemon.BaseExperience, ball)
+	fmt.Printf("Caught %s %d times out of %d (%.1f%%), expected %.1f%%\n",
+		pokemon.Name, successes, n,
+		float64(successes)/float64(n)*100,
+		CalculateCatchChance(pokemon.BaseExperience, ball)*100)
+	return nil
+}
+
+// catchLogSize is how many recent catch attempts catchlog keeps.
+const catchLogSize = 10
+
+type catchAttempt struct {
+	name   string
+	caught bool
+	at     time.Time
+	dice   int
+	chance float64
+}
+
+// catchLog is a ring buffer holding the most recent catch attempts.
+type catchLog struct {
+	attempts []catchAttempt
+	next     int
+	full     bool
+}
+
+func newCatchLog(size int) *catchLog {
+	return &catchLog{attempts: make([]catchAttempt, size)}
+}
+
+// Record stores attempt, overwriting the oldest one when the log is full.
+func (l *catchLog) Record(attempt catchAttempt) {
+	l.attempts[l.next] = attempt
+	l.next = (l.next + 1) % len(l.attempts)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Attempts returns the logged attempts, oldest first.
+func (l *catchLog) Attempts() []catchAttempt {
+	if !l.full {
+		return append([]catchAttempt(nil), l.attempts[:l.next]...)
+	}
+	return append(append([]catchAttempt(nil), l.attempts[l.next:]...), l.attempts[:l.next]...)
+}
+
+var pCatchLog = newCatchLog(catchLogSize)
+
+func commandCatchLog(params ...string) error {
+	limit, _, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	attempts := pCatchLog.Attempts()
+	if len(attempts) == 0 {
+		fmt.Println("No catch attempts yet.")
+		return nil
+	}
+	fmt.Println("Recent catch attempts:")
+	lines := make([]string, 0, len(attempts))
+	for _, attempt := range attempts {
+		outcome := "escaped"
+		if attempt.caught {
+			outcome = "caught"
+		}
+		lines = append(lines, fmt.Sprintf("  %s %-12s %-7s (roll %d, %.0f%% chance)",
+			attempt.at.Format("15:04:05"), truncateRunes(attempt.name, 12), outcome, attempt.dice, attempt.chance*100))
+	}
+	printRows(lines, limit)
+	return nil
+}
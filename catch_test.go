@@ -0,0 +1,479 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCatchRateMatchesCalculateCatchChance(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/snorlax": pokemonJSON(143, "snorlax", 189, "normal"),
+	})
+	for _, ballName := range []string{"poke", "great", "ultra", "master"} {
+		out := captureOutput(t, func() {
+			if err := commandCatchRate("snorlax", "--ball", ballName); err != nil {
+				t.Errorf("catchrate --ball %s: %v", ballName, err)
+			}
+		})
+		ball := pokeballs[ballName]
+		want := fmt.Sprintf("Chance to catch snorlax (%s): %.0f%%", ball.name, CalculateCatchChance(189, ball)*100)
+		if !strings.Contains(out, want) {
+			t.Errorf("output %q does not contain %q", out, want)
+		}
+	}
+	if _, err := pDex.Get("snorlax"); err == nil {
+		t.Error("catchrate added snorlax to the pokedex")
+	}
+}
+
+func TestCalculateCatchChance(t *testing.T) {
+	setupTest(t)
+	tests := []struct {
+		baseExperience int
+		ball           string
+		want           float64
+	}{
+		{0, "poke", 1},
+		{40, "poke", 1},
+		{100, "poke", 0.5},
+		{100, "ultra", 0.9},
+		{1000, "poke", 0.1},
+		{1000, "master", 1},
+	}
+	for _, tt := range tests {
+		if got := CalculateCatchChance(tt.baseExperience, pokeballs[tt.ball]); got != tt.want {
+			t.Errorf("CalculateCatchChance(%d, %s) = %v, want %v", tt.baseExperience, tt.ball, got, tt.want)
+		}
+	}
+}
+
+func TestCatchMathExtremes(t *testing.T) {
+	setupTest(t)
+	for _, baseExperience := range []int{math.MinInt, -1, 0, 1, catchThreshold, catchThreshold + 1, math.MaxInt32, math.MaxInt} {
+		for _, dice := range []int{0, 1, diceSides - 1, math.MaxInt} {
+			got := catchSucceeds(dice, baseExperience)
+			want := baseExperience <= 0 || dice == 0 || big.NewInt(0).Mul(big.NewInt(int64(dice)), big.NewInt(int64(baseExperience))).Cmp(big.NewInt(catchThreshold)) <= 0
+			if got != want {
+				t.Errorf("catchSucceeds(%d, %d) = %v, want %v", dice, baseExperience, got, want)
+			}
+		}
+		for name, ball := range pokeballs {
+			chance := CalculateCatchChance(baseExperience, ball)
+			if chance < 1/float64(diceSides) || chance > 1 {
+				t.Errorf("CalculateCatchChance(%d, %s) = %v, outside [1/%d, 1]", baseExperience, name, chance, diceSides)
+			}
+		}
+	}
+}
+
+func TestCatchLogKeepsTheLatestAttempts(t *testing.T) {
+	log := newCatchLog(3)
+	if got := log.Attempts(); len(got) != 0 {
+		t.Errorf("new log holds %v", got)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		log.Record(catchAttempt{name: fmt.Sprint("pokemon-", i), caught: i%2 == 0, at: start.Add(time.Duration(i) * time.Minute), dice: i})
+		want := min(i+1, 3)
+		if got := log.Attempts(); len(got) != want || got[len(got)-1].dice != i {
+			t.Errorf("after %d attempts, log holds %+v", i+1, got)
+		}
+	}
+	names := make([]string, 0, 3)
+	for _, attempt := range log.Attempts() {
+		names = append(names, attempt.name)
+	}
+	if got := strings.Join(names, " "); got != "pokemon-2 pokemon-3 pokemon-4" {
+		t.Errorf("log holds %s, want the last three attempts oldest first", got)
+	}
+}
+
+func TestCommandCatchLog(t *testing.T) {
+	clock := setupTest(t)
+	out := captureOutput(t, func() {
+		commandCatchLog()
+	})
+	if !strings.Contains(out, "No catch attempts yet.") {
+		t.Errorf("empty log output:\n%s", out)
+	}
+
+	pCatchLog.Record(catchAttempt{name: "pikachu", caught: true, at: clock.Now(), dice: 2, chance: 0.5})
+	pCatchLog.Record(catchAttempt{name: "snorlax", at: clock.Now().Add(time.Minute), dice: 9, chance: 0.2})
+	out = captureOutput(t, func() {
+		commandCatchLog()
+	})
+	for _, want := range []string{
+		"12:00:00 pikachu      caught  (roll 2, 50% chance)",
+		"12:01:00 snorlax      escaped (roll 9, 20% chance)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMoreDiceSidesMakeCatchingHarder(t *testing.T) {
+	setupTest(t)
+	const rolls = 1000
+	caught := make(map[int]int)
+	for _, sides := range []int{10, 40} {
+		diceSides = sides
+		caught[sides] = simulateCatches(rand.New(rand.NewSource(42)), rolls, 100, pokeballs["poke"])
+	}
+	// With base experience 100 only rolls 0 to 4 catch.
+	if caught[10] < rolls*4/10 || caught[10] > rolls*6/10 {
+		t.Errorf("caught %d of %d with 10 sides, want about half", caught[10], rolls)
+	}
+	if caught[40] >= caught[10] {
+		t.Errorf("caught %d with 40 sides and %d with 10, want fewer with more sides", caught[40], caught[10])
+	}
+}
+
+func TestCommandDiceSides(t *testing.T) {
+	setupTest(t)
+	captureOutput(t, func() {
+		for _, bad := range []string{"0", "-3", "many"} {
+			if err := commandDiceSides(bad); err == nil {
+				t.Errorf("dicesides %s was accepted", bad)
+			}
+		}
+		if err := commandDiceSides("20"); err != nil {
+			t.Errorf("dicesides 20: %v", err)
+		}
+	})
+	if diceSides != 20 {
+		t.Errorf("diceSides = %d, want 20", diceSides)
+	}
+}
+
+// stubSleep replaces sleep for the test and returns the pauses requested.
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var pauses []time.Duration
+	sleep = func(d time.Duration) { pauses = append(pauses, d) }
+	t.Cleanup(func() { sleep = time.Sleep })
+	return &pauses
+}
+
+func TestCatchRetries404(t *testing.T) {
+	setupTest(t)
+	pauses := stubSleep(t)
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, pokemonJSON(1025, "pecharunt", 1, "poison", "ghost"))
+	}))
+	defer srv.Close()
+	baseURL = srv.URL
+
+	out := captureOutput(t, func() {
+		if err := commandCatch("pecharunt", "--retry404", "--ball", "master"); err != nil {
+			t.Errorf("catch --retry404: %v", err)
+		}
+	})
+	if _, err := pDex.Get("pecharunt"); err != nil {
+		t.Errorf("pecharunt was not caught:\n%s", out)
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want 3", requests)
+	}
+	if len(*pauses) != 2 || (*pauses)[0] != notFoundDelay {
+		t.Errorf("paused %v, want notFoundDelay twice", *pauses)
+	}
+}
+
+func TestCatchDoesNotRetry404ByDefault(t *testing.T) {
+	setupTest(t)
+	pauses := stubSleep(t)
+	requests := stubAPI(t, map[string]string{})
+	captureOutput(t, func() {
+		if err := commandCatch("pecharunt", "--ball", "master"); !isNotFound(err) {
+			t.Errorf("catch = %v, want a 404", err)
+		}
+	})
+	// One request for the pokemon, one for the name list used to suggest a
+	// match.
+	if n := requests.Load(); n != 2 {
+		t.Errorf("made %d requests, want 2", n)
+	}
+	if len(*pauses) != 0 {
+		t.Errorf("paused %v without --retry404", *pauses)
+	}
+}
+
+func TestCatchCooldown(t *testing.T) {
+	clock := setupTest(t)
+	requests := stubAPI(t, map[string]string{
+		"/pokemon/pikachu": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	captureOutput(t, func() {
+		if err := commandCooldown("30"); err != nil {
+			t.Errorf("cooldown 30: %v", err)
+		}
+	})
+
+	throw := func() string {
+		return captureOutput(t, func() {
+			if err := commandCatch("pikachu", "--ball", "master"); err != nil {
+				t.Errorf("catch: %v", err)
+			}
+		})
+	}
+	if out := throw(); !strings.Contains(out, "Throwing a Master Ball") {
+		t.Errorf("first throw printed:\n%s", out)
+	}
+	clock.Advance(20 * time.Second)
+	if out := throw(); !strings.Contains(out, "pikachu is still cooling down, wait 10s") || strings.Contains(out, "Throwing") {
+		t.Errorf("throw during the cooldown printed:\n%s", out)
+	}
+	clock.Advance(10 * time.Second)
+	if out := throw(); !strings.Contains(out, "Throwing a Master Ball") {
+		t.Errorf("throw after the cooldown printed:\n%s", out)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+}
+
+func TestCatchCooldownFollowsThePokemon(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/25": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	captureOutput(t, func() { commandCooldown("30") })
+
+	captureOutput(t, func() { commandCatch("missingno") })
+	if remaining := pCooldown.Remaining("missingno"); remaining != 0 {
+		t.Errorf("a failed fetch started a %s cooldown", remaining)
+	}
+	captureOutput(t, func() { commandCatch("25", "--ball", "master") })
+	if remaining := pCooldown.Remaining("pikachu"); remaining != 30*time.Second {
+		t.Errorf("catching by id left pikachu a %s cooldown, want 30s", remaining)
+	}
+}
+
+func TestCatchCooldownOffByDefault(t *testing.T) {
+	setupTest(t)
+	pCooldown.Mark("pikachu")
+	if remaining := pCooldown.Remaining("pikachu"); remaining != 0 {
+		t.Errorf("Remaining = %s with no cooldown set, want 0", remaining)
+	}
+}
+
+func TestBetterBallsCatchMore(t *testing.T) {
+	setupTest(t)
+	const rolls = 1000
+	caught := make(map[string]int)
+	for _, name := range []string{"poke", "great", "ultra", "master"} {
+		ball, err := lookupBall(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		caught[name] = simulateCatches(rand.New(rand.NewSource(7)), rolls, 200, ball)
+	}
+	if !(caught["poke"] < caught["great"] && caught["great"] < caught["ultra"]) {
+		t.Errorf("catches per ball = %v, want poke < great < ultra", caught)
+	}
+	if caught["master"] != rolls {
+		t.Errorf("master ball caught %d of %d", caught["master"], rolls)
+	}
+}
+
+func TestLookupBall(t *testing.T) {
+	for name, want := range map[string]string{"": "Poke Ball", "Great": "Great Ball", "master": "Master Ball"} {
+		if ball, err := lookupBall(name); err != nil || ball.name != want {
+			t.Errorf("lookupBall(%q) = %q, %v, want %q", name, ball.name, err, want)
+		}
+	}
+	if _, err := lookupBall("dusk"); err == nil {
+		t.Error("lookupBall accepted an unknown ball")
+	}
+}
+
+func TestCatchWithUnknownBall(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{})
+	captureOutput(t, func() {
+		if err := commandCatch("pikachu", "--ball", "dusk"); err == nil {
+			t.Error("catch accepted an unknown ball")
+		}
+	})
+	if n := requests.Load(); n != 0 {
+		t.Errorf("made %d requests before rejecting the ball", n)
+	}
+}
+
+func TestCatchAnimation(t *testing.T) {
+	tests := []struct {
+		name     string
+		delay    time.Duration
+		terminal bool
+		want     int
+	}{
+		{"interactive", 200 * time.Millisecond, true, 3},
+		{"off", 0, true, 0},
+		{"piped", 200 * time.Millisecond, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			pauses := stubSleep(t)
+			catchDelay = tt.delay
+			pEditor = &lineEditor{terminal: tt.terminal}
+			out := captureOutput(t, catchAnimation)
+			if len(*pauses) != tt.want {
+				t.Errorf("paused %d times, want %d", len(*pauses), tt.want)
+			}
+			for _, d := range *pauses {
+				if d != tt.delay {
+					t.Errorf("paused for %v, want %v", d, tt.delay)
+				}
+			}
+			if dots := strings.Count(out, "."); dots != tt.want {
+				t.Errorf("printed %d dots, want %d", dots, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandCatchDelay(t *testing.T) {
+	setupTest(t)
+	captureOutput(t, func() {
+		if err := commandCatchDelay("250ms"); err != nil {
+			t.Errorf("catchdelay 250ms: %v", err)
+		}
+		if catchDelay != 250*time.Millisecond {
+			t.Errorf("catchDelay = %v, want 250ms", catchDelay)
+		}
+		for _, bad := range []string{"soon", "-1s"} {
+			if err := commandCatchDelay(bad); err == nil {
+				t.Errorf("catchdelay %s succeeded", bad)
+			}
+		}
+		if catchDelay != 250*time.Millisecond {
+			t.Errorf("an invalid delay changed catchDelay to %v", catchDelay)
+		}
+		if err := commandCatchDelay("off"); err != nil || catchDelay != 0 {
+			t.Errorf("catchdelay off = %v, catchDelay %v", err, catchDelay)
+		}
+	})
+}
+
+func TestCalculateCatchChanceCountsWinningRolls(t *testing.T) {
+	setupTest(t)
+	for _, sides := range []int{1, 6, 10, 100} {
+		diceSides = sides
+		for _, experience := range []int{0, 1, 36, 112, 189, 340, 1 << 30} {
+			successes := 0
+			for dice := 0; dice < sides; dice++ {
+				if catchSucceeds(dice, effectiveExperience(experience, pokeballs["great"])) {
+					successes++
+				}
+			}
+			want := float64(successes) / float64(sides)
+			if got := CalculateCatchChance(experience, pokeballs["great"]); got != want {
+				t.Errorf("d%d, %d experience: chance %v, want %v", sides, experience, got, want)
+			}
+		}
+	}
+
+	// Counting the rolls one by one would take forever here.
+	diceSides = math.MaxInt
+	if got := CalculateCatchChance(1<<30, pokeballs["poke"]); got != 1/float64(math.MaxInt) {
+		t.Errorf("d%d chance = %v", math.MaxInt, got)
+	}
+}
+
+func TestSimulateCatchesMatchesChance(t *testing.T) {
+	const rolls = 20000
+	for _, sides := range []int{10, 20} {
+		for _, experience := range []int{36, 112, 189, 340} {
+			for _, ballName := range []string{"poke", "ultra"} {
+				setupTest(t)
+				diceSides = sides
+				ball := pokeballs[ballName]
+				r := rand.New(rand.NewSource(int64(experience)))
+				got := float64(simulateCatches(r, rolls, experience, ball)) / rolls
+				want := CalculateCatchChance(experience, ball)
+				if math.Abs(got-want) > 0.02 {
+					t.Errorf("d%d, %d experience, %s ball: caught %.3f of rolls, want %.3f",
+						sides, experience, ballName, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCommandCatchSim(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/pikachu": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	out := captureOutput(t, func() {
+		for _, params := range [][]string{{"pikachu"}, {"pikachu", "0"}, {"pikachu", "many"}, {"pikachu", "1000001"}} {
+			if err := commandCatchSim(params...); err == nil {
+				t.Errorf("catchsim %v succeeded", params)
+			}
+		}
+		if err := commandCatchSim("pikachu", "100"); err != nil {
+			t.Errorf("catchsim pikachu 100: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Caught pikachu ") || !strings.Contains(out, " out of 100 ") {
+		t.Errorf("catchsim printed:\n%s", out)
+	}
+}
+
+func TestCatchDryRun(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/pikachu": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	captureOutput(t, func() { commandCooldown("30") })
+	profilePath = filepath.Join(t.TempDir(), "profile.json")
+
+	out := captureOutput(t, func() {
+		if err := commandCatch("pikachu", "--ball", "master", "--dry-run"); err != nil {
+			t.Errorf("catch --dry-run: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Dry run: you would have caught pikachu") {
+		t.Errorf("catch --dry-run printed:\n%s", out)
+	}
+	if pDex.Count() != 0 || pTrainer.XP != 0 || len(pCatchLog.Attempts()) != 0 {
+		t.Errorf("a dry run left %d pokemon, %d XP and %d logged attempts",
+			pDex.Count(), pTrainer.XP, len(pCatchLog.Attempts()))
+	}
+	if remaining := pCooldown.Remaining("pikachu"); remaining != 0 {
+		t.Errorf("a dry run started a %s cooldown", remaining)
+	}
+	if _, err := os.Stat(profilePath); !os.IsNotExist(err) {
+		t.Errorf("a dry run saved the profile: %v", err)
+	}
+
+	// The real throw right after still goes through.
+	out = captureOutput(t, func() {
+		if err := commandCatch("pikachu", "--ball", "master"); err != nil {
+			t.Errorf("catch: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Gotcha!") || pDex.Count() != 1 {
+		t.Errorf("catch after a dry run printed:\n%s", out)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxTypeProbes bounds how many encountered pokemon catchtype fetches while
+// looking for one of the wanted type.
+const maxTypeProbes = 10
+
+// hasType reports whether pokemon is of type typeName.
+func hasType(pokemon Pokemon, typeName string) bool {
+	for _, t := range pokemon.Types {
+		if t.Type.Name == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// findByType fetches the pokemon of encounters in order, up to
+// maxTypeProbes of them, and returns the body of the first one of type
+// typeName. Pokemon pokeapi does not know are skipped. found is false when
+// none matched.
+func findByType(encounters []string, typeName string) ([]byte, bool, error) {
+	for i, name := range encounters {
+		if i == maxTypeProbes {
+			break
+		}
+		body, err := fetchValid(pokemonPrefix+name, validatePokemon)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		var pokemon Pokemon
+		if err := json.Unmarshal(body, &pokemon); err != nil {
+			return nil, false, err
+		}
+		if hasType(pokemon, typeName) {
+			return body, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func commandCatchType(params ...string) error {
+	ballName, params := takeFlagValue(params, "ball")
+	ball, err := lookupBall(ballName)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	if len(params) < 2 {
+		fmt.Println("Usage: catchtype <location> <type>")
+		return errors.New("missing arguments")
+	}
+	location, typeName := params[0], strings.ToLower(params[1])
+	if err := checkTypeName(typeName); err != nil {
+		fmt.Println(err)
+		return err
+	}
+	data, err := fetchValid(locationAreaURL+"/"+location, validateLocationArea)
+	if err != nil {
+		return err
+	}
+	var locs PokeLocal
+	if err := json.Unmarshal(data, &locs); err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+		return err
+	}
+	encounters := make([]string, 0, len(locs.PokemonEncounters))
+	for _, enc := range locs.PokemonEncounters {
+		encounters = append(encounters, enc.Pokemon.Name)
+	}
+
+	body, found, err := findByType(encounters, typeName)
+	if err != nil {
+		fmt.Println("Error fetching pokemon:", err)
+		return err
+	}
+	if !found {
+		fmt.Printf("No %s pokemon found at %s", typeName, location)
+		if len(encounters) > maxTypeProbes {
+			fmt.Printf(" among the first %d encounters", maxTypeProbes)
+		}
+		fmt.Println()
+		return nil
+	}
+	return processCatch(body, ball, false)
+}
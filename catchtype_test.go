@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stubEncounters serves the type list and a location where missingno, which
+// pokeapi does not know, bulbasaur and pikachu can be encountered.
+func stubEncounters(t *testing.T) {
+	t.Helper()
+	stubAPI(t, map[string]string{
+		"/type?limit=100":                listJSON(len(testTypes), "", testTypes...),
+		"/location-area/viridian-forest": locationAreaJSON("viridian-forest", "missingno", "bulbasaur", "pikachu"),
+		"/pokemon/bulbasaur":             pokemonJSON(1, "bulbasaur", 64, "grass", "poison"),
+		"/pokemon/pikachu":               pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+}
+
+func TestCatchType(t *testing.T) {
+	setupTest(t)
+	stubEncounters(t)
+	out := captureOutput(t, func() {
+		if err := commandCatchType("viridian-forest", "Electric", "--ball", "master"); err != nil {
+			t.Errorf("catchtype: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Throwing a Master Ball at pikachu") || pDex.Count() != 1 {
+		t.Errorf("catchtype electric printed:\n%s", out)
+	}
+}
+
+func TestCatchTypeNoMatch(t *testing.T) {
+	setupTest(t)
+	stubEncounters(t)
+	out := captureOutput(t, func() {
+		if err := commandCatchType("viridian-forest", "water"); err != nil {
+			t.Errorf("catchtype: %v", err)
+		}
+	})
+	if !strings.HasSuffix(out, "No water pokemon found at viridian-forest\n") {
+		t.Errorf("catchtype water printed %q", out)
+	}
+}
+
+func TestCatchTypeValidatesTypeFirst(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{
+		"/type?limit=100": listJSON(len(testTypes), "", testTypes...),
+	})
+	out := captureOutput(t, func() {
+		if err := commandCatchType("viridian-forest", "fyre"); err == nil {
+			t.Error("catchtype with an unknown type succeeded")
+		}
+	})
+	if !strings.Contains(out, `unknown type "fyre"`) {
+		t.Errorf("catchtype fyre printed:\n%s", out)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want only the type list", n)
+	}
+}
+
+func TestFindByTypeStopsProbing(t *testing.T) {
+	setupTest(t)
+	bodies := make(map[string]string)
+	encounters := make([]string, 0, maxTypeProbes+1)
+	for i := 0; i <= maxTypeProbes; i++ {
+		name := fmt.Sprintf("normal-%d", i)
+		encounters = append(encounters, name)
+		bodies["/pokemon/"+name] = pokemonJSON(i+1, name, 50, "normal")
+	}
+	// Only the one past the probe limit is a fire type.
+	bodies["/pokemon/"+encounters[maxTypeProbes]] = pokemonJSON(99, encounters[maxTypeProbes], 50, "fire")
+	requests := stubAPI(t, bodies)
+
+	_, found, err := findByType(encounters, "fire")
+	if found || err != nil {
+		t.Errorf("findByType = %v, %v, want nothing found within %d probes", found, err, maxTypeProbes)
+	}
+	if n := requests.Load(); n != maxTypeProbes {
+		t.Errorf("made %d requests, want %d", n, maxTypeProbes)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// fetchEncounters returns the names of the pokemon encountered at location.
+func fetchEncounters(location string) ([]string, error) {
+	url := locationAreaURL + "/" + location
+	body, err := fetchValid(url, validateLocationArea)
+	if err != nil {
+		return nil, err
+	}
+	var locs PokeLocal
+	err = json.Unmarshal(body, &locs)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+		return nil, err
+	}
+	names := make([]string, 0, len(locs.PokemonEncounters))
+	for _, encounter := range locs.PokemonEncounters {
+		names = append(names, encounter.Pokemon.Name)
+	}
+	return names, nil
+}
+
+func commandCompareLocs(params ...string) error {
+	if len(params) < 2 {
+		fmt.Println("Please provide two location names")
+		return errors.New("two location names required")
+	}
+	first, err := fetchEncounters(params[0])
+	if err != nil {
+		return err
+	}
+	second, err := fetchEncounters(params[1])
+	if err != nil {
+		return err
+	}
+	onlyFirst, onlySecond, shared := diffSets(first, second)
+	printNameGroup("Only in "+params[0], onlyFirst)
+	printNameGroup("Only in "+params[1], onlySecond)
+	printNameGroup("In both", shared)
+	return nil
+}
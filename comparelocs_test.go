@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestCommandCompareLocs(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/location-area/canalave-city-area":  locationAreaJSON("canalave-city-area", "tentacool", "staryu", "wingull"),
+		"/location-area/sunyshore-city-area": locationAreaJSON("sunyshore-city-area", "wingull", "shellos", "tentacool"),
+	})
+	out := captureOutput(t, func() {
+		if err := commandCompareLocs("canalave-city-area", "sunyshore-city-area"); err != nil {
+			t.Errorf("comparelocs: %v", err)
+		}
+	})
+	want := "Only in canalave-city-area (1):\n  staryu\n" +
+		"Only in sunyshore-city-area (1):\n  shellos\n" +
+		"In both (2):\n  tentacool, wingull\n"
+	if out != want {
+		t.Errorf("comparelocs printed\n%s\nwant\n%s", out, want)
+	}
+}
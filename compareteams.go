@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// loadTeam reads the pokemon of the profile saved at path. Unlike
+// LoadProfile it never migrates or creates anything.
+func loadTeam(path string) ([]Pokemon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dex, _, _, err := parseProfile(data, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	team := make([]Pokemon, 0, dex.Count())
+	for _, name := range dex.Names() {
+		pokemon, err := dex.GetPokemon(name)
+		if err != nil {
+			return nil, err
+		}
+		team = append(team, pokemon)
+	}
+	return team, nil
+}
+
+// totalStats sums the base stats of pokemon.
+func totalStats(pokemon Pokemon) int {
+	total := 0
+	for _, stat := range pokemon.Stats {
+		total += stat.BaseStat
+	}
+	return total
+}
+
+// teamSummary returns the names of team, the sum of their base stats and
+// the distinct types they cover, sorted.
+func teamSummary(team []Pokemon) (names []string, total int, types []string) {
+	seen := make(map[string]bool)
+	for _, pokemon := range team {
+		names = append(names, pokemon.Name)
+		total += totalStats(pokemon)
+		for _, t := range pokemon.Types {
+			if !seen[t.Type.Name] {
+				seen[t.Type.Name] = true
+				types = append(types, t.Type.Name)
+			}
+		}
+	}
+	sort.Strings(types)
+	return names, total, types
+}
+
+func commandCompareTeams(params ...string) error {
+	if len(params) < 2 {
+		fmt.Println("Please provide two profile files")
+		return errors.New("two profile files required")
+	}
+	first, err := loadTeam(params[0])
+	if err != nil {
+		fmt.Println("Error loading team:", err)
+		return err
+	}
+	second, err := loadTeam(params[1])
+	if err != nil {
+		fmt.Println("Error loading team:", err)
+		return err
+	}
+	firstNames, firstTotal, firstTypes := teamSummary(first)
+	secondNames, secondTotal, secondTypes := teamSummary(second)
+
+	fmt.Printf("%-24s %2d pokemon, total stats %d\n", truncateRunes(params[0], 23)+":", len(first), firstTotal)
+	fmt.Printf("%-24s %2d pokemon, total stats %d\n", truncateRunes(params[1], 23)+":", len(second), secondTotal)
+	onlyFirst, onlySecond, sharedTypes := diffSets(firstTypes, secondTypes)
+	printNameGroup("Types only in "+params[0], onlyFirst)
+	printNameGroup("Types only in "+params[1], onlySecond)
+	printNameGroup("Types in both", sharedTypes)
+	_, _, shared := diffSets(firstNames, secondNames)
+	printNameGroup("Shared pokemon", shared)
+	return nil
+}
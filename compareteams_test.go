@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// saveTeam saves a profile holding bodies and returns its path.
+func saveTeam(t *testing.T, name string, bodies ...string) string {
+	t.Helper()
+	dex := NewPokedex()
+	for _, body := range bodies {
+		var pokemon Pokemon
+		if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+			t.Fatal(err)
+		}
+		dex.Add(pokemon.Name, []byte(body))
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := SaveProfile(path, dex, newTrainer()); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTeamSummary(t *testing.T) {
+	team := make([]Pokemon, 0, 3)
+	for _, body := range []string{
+		pokemonWithStats(t, 1, "bulbasaur", "hp", 45, "attack", 49),
+		pokemonWithStats(t, 2, "ivysaur", "hp", 60, "attack", 62),
+		pokemonJSON(25, "pikachu", 112, "electric"),
+	} {
+		var pokemon Pokemon
+		if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+			t.Fatal(err)
+		}
+		team = append(team, pokemon)
+	}
+	names, total, types := teamSummary(team)
+	if want := []string{"bulbasaur", "ivysaur", "pikachu"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if total != 45+49+60+62 {
+		t.Errorf("total = %d, want %d", total, 45+49+60+62)
+	}
+	if want := []string{"electric", "grass"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("types = %v, want %v", types, want)
+	}
+}
+
+func TestCompareTeams(t *testing.T) {
+	setupTest(t)
+	first := saveTeam(t, "red.json",
+		pokemonJSON(25, "pikachu", 112, "electric"),
+		pokemonJSON(1, "bulbasaur", 64, "grass", "poison"))
+	second := saveTeam(t, "blue.json",
+		pokemonJSON(25, "pikachu", 112, "electric"),
+		pokemonJSON(7, "squirtle", 63, "water"))
+
+	out := captureOutput(t, func() {
+		if err := commandCompareTeams(first, second); err != nil {
+			t.Errorf("compareteams: %v", err)
+		}
+	})
+	for _, want := range []string{" 2 pokemon", "grass", "poison", "water", "electric", "pikachu"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "bulbasaur") || strings.Contains(out, "squirtle") {
+		t.Errorf("output lists a pokemon only one team has as shared:\n%s", out)
+	}
+	if pDex.Count() != 0 {
+		t.Errorf("comparing teams added %d pokemon to the pokedex", pDex.Count())
+	}
+}
+
+func TestCompareTeamsErrors(t *testing.T) {
+	setupTest(t)
+	team := saveTeam(t, "red.json", pokemonJSON(25, "pikachu", 112, "electric"))
+	captureOutput(t, func() {
+		if err := commandCompareTeams(team); err == nil {
+			t.Error("compareteams with one file succeeded")
+		}
+		if err := commandCompareTeams(team, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+			t.Error("compareteams with a missing file succeeded")
+		}
+	})
+}
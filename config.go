@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config gathers every setting read from the environment. It is loaded once
+// at startup; the components are set up from it rather than reading the
+// environment themselves.
+type Config struct {
+	// BaseURL is the pokeapi host requests go to (POKEDEX_BASE_URL). A
+	// file:// URL serves JSON fixtures from a local directory instead.
+	BaseURL string
+	// FixtureDir is the directory of a file:// BaseURL.
+	FixtureDir string
+	// Mirrors are tried when BaseURL fails (POKEDEX_MIRRORS).
+	Mirrors []string
+	// Timeout bounds a whole HTTP request (POKEDEX_TIMEOUT).
+	Timeout time.Duration
+	// CacheTTL is how long cached responses live (POKEDEX_CACHE_TTL).
+	CacheTTL time.Duration
+	// CacheDir overrides where the disk cache lives (POKEDEX_CACHE_DIR).
+	CacheDir string
+	// RateLimit is the minimum delay between requests (POKEDEX_RATE_LIMIT).
+	RateLimit time.Duration
+	// MaxConcurrency caps the requests in flight at once
+	// (POKEDEX_MAX_CONCURRENCY).
+	MaxConcurrency int
+	// CacheMaxBytes caps the size of the cached bodies, evicting the oldest
+	// beyond it (POKEDEX_CACHE_MAX_BYTES). Zero means no limit.
+	CacheMaxBytes int
+	// CacheDedupe stores identical cached bodies only once
+	// (POKEDEX_CACHE_DEDUPE).
+	CacheDedupe bool
+	// CacheSliding restarts the lifetime of cached entries whenever they are
+	// read (POKEDEX_CACHE_SLIDING).
+	CacheSliding bool
+	// Offline refuses network access (POKEDEX_OFFLINE).
+	Offline bool
+	// Debug logs cache operations to stderr (POKEDEX_DEBUG).
+	Debug bool
+	// PrettyJSON indents the disk cache and cache exports
+	// (POKEDEX_PRETTY_JSON). They are compact by default.
+	PrettyJSON bool
+	// NoColor turns colored output off (NO_COLOR).
+	NoColor bool
+	// Seed makes random rolls repeatable when set (POKEDEX_SEED).
+	Seed string
+	// UserAgent identifies us to pokeapi (POKEDEX_USER_AGENT).
+	UserAgent string
+	// TrainerName overrides the name saved in the profile
+	// (POKEDEX_TRAINER_NAME).
+	TrainerName string
+	// AliasesPath overrides the aliases file (POKEDEX_ALIASES).
+	AliasesPath string
+}
+
+// defaultTimeout bounds a request when POKEDEX_TIMEOUT is unset.
+const defaultTimeout = 30 * time.Second
+
+// LoadConfig reads the configuration through getenv, usually os.Getenv.
+// Invalid values are reported together in the error and replaced by their
+// defaults, so the returned Config is always usable.
+func LoadConfig(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		BaseURL:        primaryBaseURL,
+		Mirrors:        parseMirrors(getenv("POKEDEX_MIRRORS")),
+		Timeout:        defaultTimeout,
+		CacheTTL:       5 * time.Minute,
+		CacheDir:       getenv("POKEDEX_CACHE_DIR"),
+		RateLimit:      requestInterval,
+		MaxConcurrency: defaultConcurrency,
+		NoColor:        getenv("NO_COLOR") != "",
+		Seed:           getenv("POKEDEX_SEED"),
+		UserAgent:      defaultUserAgent,
+		TrainerName:    strings.TrimSpace(getenv("POKEDEX_TRAINER_NAME")),
+		AliasesPath:    getenv("POKEDEX_ALIASES"),
+	}
+	var errs []error
+
+	if raw := getenv("POKEDEX_BASE_URL"); raw != "" {
+		u, err := url.Parse(raw)
+		switch {
+		case err == nil && u.Scheme == "file" && u.Path != "":
+			cfg.BaseURL = "file://" + filepath.Clean(u.Path)
+			cfg.FixtureDir = filepath.Clean(u.Path)
+		case err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "":
+			cfg.BaseURL = strings.TrimSuffix(raw, "/")
+		default:
+			errs = append(errs, fmt.Errorf("POKEDEX_BASE_URL: %q is not an http(s) or file URL", raw))
+		}
+	}
+	if ua := getenv("POKEDEX_USER_AGENT"); ua != "" {
+		cfg.UserAgent = ua
+	}
+	if err := parseDuration(getenv, "POKEDEX_TIMEOUT", &cfg.Timeout, false); err != nil {
+		errs = append(errs, err)
+	}
+	if err := parseDuration(getenv, "POKEDEX_CACHE_TTL", &cfg.CacheTTL, false); err != nil {
+		errs = append(errs, err)
+	}
+	if err := parseDuration(getenv, "POKEDEX_RATE_LIMIT", &cfg.RateLimit, true); err != nil {
+		errs = append(errs, err)
+	}
+	if raw := getenv("POKEDEX_MAX_CONCURRENCY"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			errs = append(errs, fmt.Errorf("POKEDEX_MAX_CONCURRENCY: %q is not a positive number", raw))
+		} else {
+			cfg.MaxConcurrency = n
+		}
+	}
+	if raw := getenv("POKEDEX_CACHE_MAX_BYTES"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("POKEDEX_CACHE_MAX_BYTES: %q is not a number of bytes", raw))
+		} else {
+			cfg.CacheMaxBytes = n
+		}
+	}
+	if err := parseBool(getenv, "POKEDEX_CACHE_DEDUPE", &cfg.CacheDedupe); err != nil {
+		errs = append(errs, err)
+	}
+	if err := parseBool(getenv, "POKEDEX_CACHE_SLIDING", &cfg.CacheSliding); err != nil {
+		errs = append(errs, err)
+	}
+	if err := parseBool(getenv, "POKEDEX_OFFLINE", &cfg.Offline); err != nil {
+		errs = append(errs, err)
+	}
+	if err := parseBool(getenv, "POKEDEX_DEBUG", &cfg.Debug); err != nil {
+		errs = append(errs, err)
+	}
+	if err := parseBool(getenv, "POKEDEX_PRETTY_JSON", &cfg.PrettyJSON); err != nil {
+		errs = append(errs, err)
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// parseDuration sets *d from the variable name when it is set. Zero is only
+// accepted when allowZero is true; negative durations never are.
+func parseDuration(getenv func(string) string, name string, d *time.Duration, allowZero bool) error {
+	raw := getenv(name)
+	if raw == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil || parsed < 0 || (parsed == 0 && !allowZero) {
+		return fmt.Errorf("%s: %q is not a valid duration", name, raw)
+	}
+	*d = parsed
+	return nil
+}
+
+// parseBool sets *b from the variable name when it is set, accepting 1/0,
+// true/false and the other forms of strconv.ParseBool.
+func parseBool(getenv func(string) string, name string, b *bool) error {
+	raw := getenv(name)
+	if raw == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(raw)
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a boolean", name, raw)
+	}
+	*b = parsed
+	return nil
+}
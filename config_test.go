@@ -0,0 +1,161 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a getenv reading vars.
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := LoadConfig(env(nil))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	want := Config{
+		BaseURL:        primaryBaseURL,
+		Mirrors:        []string{},
+		Timeout:        defaultTimeout,
+		CacheTTL:       5 * time.Minute,
+		RateLimit:      requestInterval,
+		MaxConcurrency: defaultConcurrency,
+		UserAgent:      defaultUserAgent,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("LoadConfig() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(env(map[string]string{
+		"POKEDEX_BASE_URL":        "https://mirror.example/api/v2/",
+		"POKEDEX_MIRRORS":         "https://a.example/api/v2",
+		"POKEDEX_TIMEOUT":         "5s",
+		"POKEDEX_CACHE_TTL":       "1h",
+		"POKEDEX_CACHE_DIR":       "/tmp/cache",
+		"POKEDEX_RATE_LIMIT":      "0",
+		"POKEDEX_MAX_CONCURRENCY": "2",
+		"POKEDEX_CACHE_MAX_BYTES": "1024",
+		"POKEDEX_CACHE_DEDUPE":    "true",
+		"POKEDEX_CACHE_SLIDING":   "1",
+		"POKEDEX_OFFLINE":         "sometimes",
+		"POKEDEX_DEBUG":           "1",
+		"POKEDEX_PRETTY_JSON":     "t",
+		"NO_COLOR":                "1",
+		"POKEDEX_SEED":            "42",
+		"POKEDEX_USER_AGENT":      "test/1.0",
+		"POKEDEX_TRAINER_NAME":    "  Red  ",
+		"POKEDEX_ALIASES":         "/tmp/aliases",
+	}))
+	if err == nil || !strings.Contains(err.Error(), "POKEDEX_OFFLINE") {
+		t.Errorf("LoadConfig error = %v, want POKEDEX_OFFLINE reported", err)
+	}
+	want := Config{
+		BaseURL:        "https://mirror.example/api/v2",
+		Mirrors:        []string{"https://a.example/api/v2"},
+		Timeout:        5 * time.Second,
+		CacheTTL:       time.Hour,
+		CacheDir:       "/tmp/cache",
+		RateLimit:      0,
+		MaxConcurrency: 2,
+		CacheMaxBytes:  1024,
+		CacheDedupe:    true,
+		CacheSliding:   true,
+		Debug:          true,
+		PrettyJSON:     true,
+		NoColor:        true,
+		Seed:           "42",
+		UserAgent:      "test/1.0",
+		TrainerName:    "Red",
+		AliasesPath:    "/tmp/aliases",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("LoadConfig() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadConfigFileBaseURL(t *testing.T) {
+	cfg, err := LoadConfig(env(map[string]string{"POKEDEX_BASE_URL": "file:///tmp/fixtures/"}))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.BaseURL != "file:///tmp/fixtures" || cfg.FixtureDir != "/tmp/fixtures" {
+		t.Errorf("BaseURL = %q, FixtureDir = %q", cfg.BaseURL, cfg.FixtureDir)
+	}
+}
+
+func TestLoadConfigInvalidValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"POKEDEX_BASE_URL", "ftp://example.com"},
+		{"POKEDEX_BASE_URL", "not a url"},
+		{"POKEDEX_TIMEOUT", "0"},
+		{"POKEDEX_TIMEOUT", "soon"},
+		{"POKEDEX_CACHE_TTL", "-1m"},
+		{"POKEDEX_RATE_LIMIT", "-1s"},
+		{"POKEDEX_MAX_CONCURRENCY", "0"},
+		{"POKEDEX_CACHE_MAX_BYTES", "-1"},
+		{"POKEDEX_CACHE_MAX_BYTES", "lots"},
+		{"POKEDEX_CACHE_DEDUPE", "maybe"},
+		{"POKEDEX_CACHE_SLIDING", "maybe"},
+		{"POKEDEX_PRETTY_JSON", "maybe"},
+	}
+	defaults, _ := LoadConfig(env(nil))
+	for _, tt := range tests {
+		cfg, err := LoadConfig(env(map[string]string{tt.name: tt.value}))
+		if err == nil || !strings.Contains(err.Error(), tt.name) {
+			t.Errorf("%s=%q: error = %v, want it reported", tt.name, tt.value, err)
+		}
+		if !reflect.DeepEqual(cfg, defaults) {
+			t.Errorf("%s=%q: config = %+v, want the defaults", tt.name, tt.value, cfg)
+		}
+	}
+}
+
+func TestLoadConfigReportsEveryError(t *testing.T) {
+	_, err := LoadConfig(env(map[string]string{
+		"POKEDEX_TIMEOUT":         "soon",
+		"POKEDEX_MAX_CONCURRENCY": "none",
+	}))
+	if err == nil {
+		t.Fatal("LoadConfig accepted two invalid values")
+	}
+	for _, name := range []string{"POKEDEX_TIMEOUT", "POKEDEX_MAX_CONCURRENCY"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not mention %s", err, name)
+		}
+	}
+}
+
+func TestSetupAppliesConfig(t *testing.T) {
+	setupTest(t)
+	t.Setenv("HOME", t.TempDir())
+	cfg, err := LoadConfig(env(map[string]string{
+		"POKEDEX_BASE_URL":     "https://mirror.example.com/api/v2",
+		"POKEDEX_CACHE_DIR":    t.TempDir(),
+		"POKEDEX_OFFLINE":      "1",
+		"POKEDEX_TRAINER_NAME": "Ash",
+		"POKEDEX_SEED":         "7",
+	}))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	setup(cfg)
+	if baseURL != cfg.BaseURL || cacheDir != cfg.CacheDir || !offline {
+		t.Errorf("after setup baseURL = %q, cacheDir = %q, offline = %v", baseURL, cacheDir, offline)
+	}
+	if pTrainer.Name != "Ash" {
+		t.Errorf("trainer name = %q, want Ash", pTrainer.Name)
+	}
+	seeded := newRNG("7")
+	if w := pickWeather(seeded); w.name != currentWeather.name || rng.Int63() != seeded.Int63() {
+		t.Errorf("rng is not seeded from POKEDEX_SEED")
+	}
+}
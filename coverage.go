@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+)
+
+// pseudoTypes are listed by pokeapi but no pokemon is of them, so coverage
+// leaves them out.
+var pseudoTypes = map[string]bool{"unknown": true, "shadow": true, "stellar": true}
+
+// typeCoverage splits allTypes into those at least one pokemon of team has
+// and those none has, keeping the order of allTypes.
+func typeCoverage(allTypes []string, team []Pokemon) (covered, missing []string) {
+	_, _, teamTypes := teamSummary(team)
+	has := make(map[string]bool, len(teamTypes))
+	for _, t := range teamTypes {
+		has[t] = true
+	}
+	for _, t := range allTypes {
+		if pseudoTypes[t] {
+			continue
+		}
+		if has[t] {
+			covered = append(covered, t)
+		} else {
+			missing = append(missing, t)
+		}
+	}
+	return covered, missing
+}
+
+func commandCoverage(params ...string) error {
+	var team []Pokemon
+	if len(params) > 0 {
+		var err error
+		team, err = loadTeam(params[0])
+		if err != nil {
+			fmt.Println("Error loading team:", err)
+			return err
+		}
+	} else {
+		for _, name := range pDex.Names() {
+			pokemon, err := pDex.GetPokemon(name)
+			if err != nil {
+				fmt.Println("Error reading pokemon:", name, err)
+				return err
+			}
+			team = append(team, pokemon)
+		}
+	}
+	allTypes, err := typeNames()
+	if err != nil {
+		return err
+	}
+	covered, missing := typeCoverage(allTypes, team)
+	printNameGroup("Covered types", covered)
+	printNameGroup("Missing types", missing)
+	return nil
+}
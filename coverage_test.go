@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypeCoverage(t *testing.T) {
+	var team []Pokemon
+	for _, body := range []string{
+		pokemonJSON(25, "pikachu", 112, "electric"),
+		pokemonJSON(6, "charizard", 240, "fire", "flying"),
+	} {
+		var pokemon Pokemon
+		if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+			t.Fatal(err)
+		}
+		team = append(team, pokemon)
+	}
+	allTypes := append([]string{"unknown"}, testTypes...)
+	allTypes = append(allTypes, "shadow", "stellar")
+
+	covered, missing := typeCoverage(allTypes, team)
+	if want := []string{"fire", "electric", "flying"}; !reflect.DeepEqual(covered, want) {
+		t.Errorf("covered = %v, want %v", covered, want)
+	}
+	if want := []string{"normal", "water", "grass", "ground", "dragon"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v", missing, want)
+	}
+
+	covered, missing = typeCoverage(allTypes, nil)
+	if len(covered) != 0 || len(missing) != len(testTypes) {
+		t.Errorf("an empty team covers %v and misses %v", covered, missing)
+	}
+}
+
+func TestCommandCoverage(t *testing.T) {
+	setupTest(t)
+	stubTypes(t)
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	out := captureOutput(t, func() {
+		if err := commandCoverage(); err != nil {
+			t.Errorf("coverage: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Covered types (1):\n  electric\n") ||
+		!strings.Contains(out, "Missing types (7):") {
+		t.Errorf("coverage printed:\n%s", out)
+	}
+
+	team := saveTeam(t, "blue.json", pokemonJSON(7, "squirtle", 63, "water"))
+	out = captureOutput(t, func() {
+		if err := commandCoverage(team); err != nil {
+			t.Errorf("coverage %s: %v", team, err)
+		}
+	})
+	if !strings.Contains(out, "Covered types (1):\n  water\n") {
+		t.Errorf("coverage of a saved team printed:\n%s", out)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// cryURL returns the pokemon's latest cry, falling back to the legacy one.
+func cryURL(pokemon Pokemon) (string, error) {
+	if url := strings.TrimSpace(pokemon.Cries.Latest); url != "" {
+		return url, nil
+	}
+	if url := strings.TrimSpace(pokemon.Cries.Legacy); url != "" {
+		return url, nil
+	}
+	return "", fmt.Errorf("%s has no cry", pokemon.Name)
+}
+
+func commandCry(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a Pokemon name")
+		return errors.New("no Pokemon name provided")
+	}
+	pokemon, err := pDex.GetPokemon(params[0])
+	if err != nil {
+		fmt.Println("You have not caught that pokemon yet (or there was an error):", params[0])
+		return err
+	}
+	url, err := cryURL(pokemon)
+	if err != nil {
+		fmt.Println("No cry available for", pokemon.Name)
+		return err
+	}
+	fmt.Printf("%s's cry: %s\n", pokemon.Name, url)
+	return nil
+}
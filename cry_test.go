@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCryURL(t *testing.T) {
+	const (
+		latest = "https://raw.githubusercontent.com/PokeAPI/cries/main/cries/pokemon/latest/25.ogg"
+		legacy = "https://raw.githubusercontent.com/PokeAPI/cries/main/cries/pokemon/legacy/25.ogg"
+	)
+	tests := []struct {
+		name           string
+		latest, legacy string
+		want           string
+		wantErr        bool
+	}{
+		{"latest present", latest, legacy, latest, false},
+		{"latest empty, legacy present", "", legacy, legacy, false},
+		{"latest blank, legacy present", "  ", legacy, legacy, false},
+		{"both empty", "", "", "", true},
+	}
+	for _, tt := range tests {
+		var pokemon Pokemon
+		pokemon.Name = "pikachu"
+		pokemon.Cries.Latest = tt.latest
+		pokemon.Cries.Legacy = tt.legacy
+		got, err := cryURL(pokemon)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("%s: cryURL = %q, %v, want %q (error %v)", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
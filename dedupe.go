@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Dedupe collapses entries holding the same pokemon (e.g. one stored under
+// its id and one under its name) into a single entry keyed by name, keeping
+// the most recently added data. It returns how many entries were merged away.
+func (p *pokedex) Dedupe() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	newest := make(map[string]pokemonEntry)
+	for key, entry := range p.entries {
+		var pokemon Pokemon
+		if err := json.Unmarshal(entry.data, &pokemon); err != nil {
+			return 0, fmt.Errorf("entry %s: %w", key, err)
+		}
+		if kept, ok := newest[pokemon.Name]; !ok || entry.createdAt.After(kept.createdAt) {
+			newest[pokemon.Name] = entry
+		}
+	}
+
+	merged := len(p.entries) - len(newest)
+	p.entries = newest
+	p.count.Store(int64(len(newest)))
+	return merged, nil
+}
+
+func commandDedupe(params ...string) error {
+	merged, err := pDex.Dedupe()
+	if err != nil {
+		fmt.Println("Error deduplicating pokedex:", err)
+		return err
+	}
+	fmt.Printf("Merged %d duplicate entries.\n", merged)
+	return saveProfile()
+}
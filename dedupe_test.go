@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDedupeKeepsTheNewestEntry(t *testing.T) {
+	clock := setupTest(t)
+	pDex.Add("25", []byte(pokemonJSON(25, "pikachu", 100, "electric")))
+	clock.Advance(time.Minute)
+	pDex.Add("pikachu", []byte(pokemonJSON(25, "pikachu", 112, "electric")))
+	clock.Advance(time.Minute)
+	pDex.Add("raichu", []byte(pokemonJSON(26, "raichu", 218, "electric")))
+	// Stored under a name, but the data is a mew stored later.
+	pDex.Add("mew-old", []byte(pokemonJSON(151, "mew", 1, "psychic")))
+	clock.Advance(time.Minute)
+	pDex.Add("151", []byte(pokemonJSON(151, "mew", 300, "psychic")))
+
+	merged, err := pDex.Dedupe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != 2 {
+		t.Errorf("merged %d entries, want 2", merged)
+	}
+	if got, want := pDex.Names(), []string{"mew", "pikachu", "raichu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	if pDex.Count() != 3 {
+		t.Errorf("Count() = %d, want 3", pDex.Count())
+	}
+	for name, want := range map[string]int{"pikachu": 112, "mew": 300} {
+		pokemon, err := pDex.GetPokemon(name)
+		if err != nil || pokemon.BaseExperience != want {
+			t.Errorf("%s has base experience %d (%v), want the newest entry's %d", name, pokemon.BaseExperience, err, want)
+		}
+	}
+
+	if merged, _ := pDex.Dedupe(); merged != 0 {
+		t.Errorf("second Dedupe merged %d entries, want 0", merged)
+	}
+}
+
+func TestDedupeRejectsCorruptEntry(t *testing.T) {
+	setupTest(t)
+	pDex.Add("broken", []byte("{"))
+	if _, err := pDex.Dedupe(); err == nil {
+		t.Error("Dedupe accepted an entry that is not JSON")
+	}
+}
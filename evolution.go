@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type EvolutionChain struct {
+	ID    int `json:"id"`
+	Chain struct {
+		Species namedResource `json:"species"`
+	} `json:"chain"`
+}
+
+// baseSpecies returns the species at the start of p's evolution chain,
+// fetched through the cache.
+func baseSpecies(p Pokemon) (namedResource, error) {
+	species, err := fetchSpecies(p)
+	if err != nil {
+		return namedResource{}, err
+	}
+	if species.EvolutionChain.URL == "" {
+		return namedResource{}, errors.New("no evolution chain URL")
+	}
+	body, err := fetch(species.EvolutionChain.URL)
+	if err != nil {
+		return namedResource{}, err
+	}
+	var chain EvolutionChain
+	err = json.Unmarshal(body, &chain)
+	if err != nil {
+		return namedResource{}, err
+	}
+	return chain.Chain.Species, nil
+}
+
+// statDeltas describes how each of evolved's stats differs from base's, e.g.
+// "+20 attack". Stats base does not have are left out.
+func statDeltas(base, evolved Pokemon) []string {
+	baseStats := make(map[string]int, len(base.Stats))
+	for _, stat := range base.Stats {
+		baseStats[stat.Stat.Name] = stat.BaseStat
+	}
+	deltas := make([]string, 0, len(evolved.Stats))
+	for _, stat := range evolved.Stats {
+		baseStat, ok := baseStats[stat.Stat.Name]
+		if !ok {
+			continue
+		}
+		deltas = append(deltas, fmt.Sprintf("%+d %s", stat.BaseStat-baseStat, stat.Stat.Name))
+	}
+	return deltas
+}
+
+// printBaseDeltas prints pokemon's stat gains over the base form of its
+// evolution chain, the default variety of the chain's first species.
+func printBaseDeltas(pokemon Pokemon) error {
+	baseRef, err := baseSpecies(pokemon)
+	if err != nil {
+		fmt.Println("Error finding base form:", err)
+		return err
+	}
+	if baseRef.Name == pokemon.Species.Name {
+		fmt.Println("Base form: no stat changes")
+		return nil
+	}
+	species, err := fetchSpeciesAt(baseRef.URL)
+	if err != nil {
+		fmt.Println("Error finding base form:", err)
+		return err
+	}
+	varietyURL, err := species.defaultVariety()
+	if err != nil {
+		fmt.Println("Error finding base form:", err)
+		return err
+	}
+	body, err := fetchValid(varietyURL, validatePokemon)
+	if err != nil {
+		return err
+	}
+	var base Pokemon
+	err = json.Unmarshal(body, &base)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+		return err
+	}
+	fmt.Printf("Compared to %s:\n", base.Name)
+	for _, delta := range statDeltas(base, pokemon) {
+		fmt.Println("  ", delta)
+	}
+	return nil
+}
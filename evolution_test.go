@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pokemonWithStats is pokemonJSON with the given base stats, in order.
+func pokemonWithStats(t *testing.T, id int, name string, stats ...any) string {
+	t.Helper()
+	var pokemon map[string]any
+	if err := json.Unmarshal([]byte(pokemonJSON(id, name, 64, "grass")), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	list := make([]any, 0, len(stats)/2)
+	for i := 0; i+1 < len(stats); i += 2 {
+		list = append(list, map[string]any{
+			"base_stat": stats[i+1],
+			"stat":      map[string]string{"name": stats[i].(string)},
+		})
+	}
+	pokemon["stats"] = list
+	data, err := json.Marshal(pokemon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestStatDeltas(t *testing.T) {
+	parse := func(body string) Pokemon {
+		var p Pokemon
+		if err := json.Unmarshal([]byte(body), &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	tests := []struct {
+		name    string
+		base    string
+		evolved string
+		want    []string
+	}{
+		{
+			name:    "gains and losses",
+			base:    pokemonWithStats(t, 1, "bulbasaur", "hp", 45, "attack", 49, "speed", 45),
+			evolved: pokemonWithStats(t, 2, "ivysaur", "hp", 60, "attack", 62, "speed", 40),
+			want:    []string{"+15 hp", "+13 attack", "-5 speed"},
+		},
+		{
+			name:    "unchanged",
+			base:    pokemonWithStats(t, 1, "bulbasaur", "hp", 45),
+			evolved: pokemonWithStats(t, 2, "ivysaur", "hp", 45),
+			want:    []string{"+0 hp"},
+		},
+		{
+			name:    "stat missing from base",
+			base:    pokemonWithStats(t, 1, "bulbasaur", "hp", 45),
+			evolved: pokemonWithStats(t, 2, "ivysaur", "hp", 60, "attack", 62),
+			want:    []string{"+15 hp"},
+		},
+		{
+			name:    "no stats",
+			base:    pokemonWithStats(t, 1, "bulbasaur"),
+			evolved: pokemonWithStats(t, 2, "ivysaur"),
+			want:    []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statDeltas(parse(tt.base), parse(tt.evolved))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statDeltas = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectBase(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon-species/2": `{"id":2,"name":"ivysaur","evolution_chain":{"url":"` + primaryBaseURL + `/evolution-chain/1"}}`,
+		"/pokemon-species/1": `{"id":1,"name":"bulbasaur","evolution_chain":{"url":"` + primaryBaseURL + `/evolution-chain/1"},` +
+			`"varieties":[{"is_default":true,"pokemon":{"name":"bulbasaur","url":"` + primaryBaseURL + `/pokemon/1"}}]}`,
+		"/evolution-chain/1": `{"id":1,"chain":{"species":{"name":"bulbasaur","url":"` + primaryBaseURL + `/pokemon-species/1"}}}`,
+		"/pokemon/1":         pokemonWithStats(t, 1, "bulbasaur", "hp", 45, "attack", 49),
+	})
+	catchPokemon(t, pokemonWithStats(t, 2, "ivysaur", "hp", 60, "attack", 62))
+	catchPokemon(t, pokemonWithStats(t, 1, "bulbasaur", "hp", 45, "attack", 49))
+
+	out := captureOutput(t, func() {
+		if err := commandInspect("ivysaur", "--base"); err != nil {
+			t.Errorf("inspect ivysaur --base: %v", err)
+		}
+	})
+	for _, want := range []string{"Compared to bulbasaur:", "+15 hp", "+13 attack"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	out = captureOutput(t, func() {
+		if err := commandInspect("bulbasaur", "--base"); err != nil {
+			t.Errorf("inspect bulbasaur --base: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Base form: no stat changes") {
+		t.Errorf("inspecting a base form printed:\n%s", out)
+	}
+}
+
+func TestInspectBaseUsesDefaultVariety(t *testing.T) {
+	setupTest(t)
+	// pokeapi has no pokemon named basculin, only its forms.
+	stubAPI(t, map[string]string{
+		"/pokemon-species/902": `{"id":902,"name":"basculegion","evolution_chain":{"url":"` + primaryBaseURL + `/evolution-chain/288"}}`,
+		"/evolution-chain/288": `{"id":288,"chain":{"species":{"name":"basculin","url":"` + primaryBaseURL + `/pokemon-species/550"}}}`,
+		"/pokemon-species/550": `{"id":550,"name":"basculin","varieties":[` +
+			`{"is_default":false,"pokemon":{"name":"basculin-blue-striped","url":"` + primaryBaseURL + `/pokemon/10016"}},` +
+			`{"is_default":true,"pokemon":{"name":"basculin-red-striped","url":"` + primaryBaseURL + `/pokemon/550"}}]}`,
+		"/pokemon/550": pokemonWithStats(t, 550, "basculin-red-striped", "hp", 70),
+	})
+	var pokemon map[string]any
+	if err := json.Unmarshal([]byte(pokemonWithStats(t, 902, "basculegion-male", "hp", 120)), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	pokemon["species"] = map[string]string{"name": "basculegion", "url": primaryBaseURL + "/pokemon-species/902"}
+	body, _ := json.Marshal(pokemon)
+	catchPokemon(t, string(body))
+
+	out := captureOutput(t, func() {
+		if err := commandInspect("basculegion-male", "--base"); err != nil {
+			t.Errorf("inspect basculegion-male --base: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Compared to basculin-red-striped:") || !strings.Contains(out, "+50 hp") {
+		t.Errorf("inspect --base printed:\n%s", out)
+	}
+}
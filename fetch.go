@@ -0,0 +1,373 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errOffline = errors.New("offline mode is on")
+
+// statusError reports a non-200 response from pokeapi.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected response: " + e.status
+}
+
+// isNotFound reports whether err is a 404 from pokeapi.
+func isNotFound(err error) bool {
+	var statusErr *statusError
+	return errors.As(err, &statusErr) && statusErr.code == http.StatusNotFound
+}
+
+// requestInterval is the minimum delay between two requests to pokeapi.
+const requestInterval = 100 * time.Millisecond
+
+// rateLimiter spaces out outbound requests by at least interval.
+type rateLimiter struct {
+	interval time.Duration
+	last     time.Time
+	mu       sync.Mutex
+}
+
+// Wait blocks until the next request is allowed.
+func (r *rateLimiter) Wait() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if wait := r.interval - time.Since(r.last); wait > 0 {
+		time.Sleep(wait)
+	}
+	r.last = time.Now()
+}
+
+var limiter = &rateLimiter{interval: requestInterval}
+
+// defaultConcurrency is how many requests may be in flight at once unless
+// POKEDEX_MAX_CONCURRENCY says otherwise.
+const defaultConcurrency = 4
+
+// semaphore bounds how many goroutines hold it at once.
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	return make(semaphore, n)
+}
+
+func (s semaphore) Acquire() { s <- struct{}{} }
+func (s semaphore) Release() { <-s }
+
+// requestSlots limits the outbound requests of every command together, so
+// concurrent helpers such as fetchMoves and fetchTeam cannot overwhelm
+// pokeapi between them.
+var requestSlots = newSemaphore(defaultConcurrency)
+
+// remoteRequests counts the HTTP requests sent, to pokeapi, its mirrors or
+// the sprite hosts.
+var remoteRequests atomic.Int64
+
+// defaultUserAgent identifies us to pokeapi.
+const defaultUserAgent = "pokedex-cli/1.0 (+github.com/ablanchetMD/pokedex)"
+
+// userAgent is sent with every request, see Config.UserAgent.
+var userAgent = defaultUserAgent
+
+// httpClient is shared by every request. Its default transport honors
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+var httpClient = &http.Client{}
+
+// SetTransport routes every request through rt, e.g. for a custom proxy or
+// TLS setup, or a stub in tests. A nil rt restores the default transport.
+func SetTransport(rt http.RoundTripper) {
+	httpClient.Transport = rt
+}
+
+// canonicalizeURL returns the form of rawURL used as a cache key, so that
+// equivalent requests share a single entry: "pokemon/25" and "pokemon/25/"
+// match, and so do "?offset=20&limit=20" and "?limit=20&offset=20".
+func canonicalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	// Encode sorts the parameters by key.
+	u.RawQuery = u.Query().Encode()
+	return u.String()
+}
+
+// fetch returns the body for rawURL, serving it from pCache when possible
+// and caching it otherwise.
+func fetch(rawURL string) ([]byte, error) {
+	return fetchValid(rawURL, nil)
+}
+
+// fetchValid is like fetch, but a fresh body is only cached and returned if
+// validate (when non-nil) accepts it.
+func fetchValid(rawURL string, validate func([]byte) error) ([]byte, error) {
+	key := canonicalizeURL(rawURL)
+
+	// Check the cache
+	data, err := pCache.Get(key)
+	if err == nil {
+		// Cache hit
+		return data, nil
+	}
+
+	body, err := fetchRemote(rawURL, validate)
+	if err != nil {
+		return nil, err
+	}
+	// Cache the response body
+	err = cacheBody(key, body)
+	if err != nil {
+		fmt.Println("Error adding to cache:", err)
+		return nil, err
+	}
+	return body, nil
+}
+
+// Lifetimes of the resources that outlive the cache's default TTL: pokemon
+// data almost never changes and the location list rarely does.
+const (
+	pokemonTTL      = 24 * time.Hour
+	locationListTTL = time.Hour
+)
+
+// locationAreaURL lists the location-areas, page by page.
+const locationAreaURL = primaryBaseURL + "/location-area"
+
+// ttlFor returns how long the body cached under key lives, or zero for the
+// cache's default TTL.
+func ttlFor(key string) time.Duration {
+	if id, ok := strings.CutPrefix(key, pokemonPrefix); ok && id != "" && !strings.ContainsAny(id, "/?") {
+		return pokemonTTL
+	}
+	if rest, ok := strings.CutPrefix(key, locationAreaURL); ok && (rest == "" || strings.HasPrefix(rest, "?")) {
+		return locationListTTL
+	}
+	return 0
+}
+
+// cacheBody stores body under key, for as long as ttlFor says. A pokemon
+// fetched by name is stored under its id URL instead, with key as an alias,
+// so "pokemon/pikachu" and "pokemon/25" share one entry.
+func cacheBody(key string, body []byte) error {
+	if idKey, ok := pokemonIDKey(key, body); ok && idKey != key {
+		if err := pCache.AddWithTTL(idKey, body, ttlFor(idKey)); err != nil {
+			return err
+		}
+		pCache.AddAlias(key, idKey)
+		return nil
+	}
+	return pCache.AddWithTTL(key, body, ttlFor(key))
+}
+
+// pokemonIDKey returns the canonical id URL of the pokemon in body, when key
+// is the URL of a single pokemon.
+func pokemonIDKey(key string, body []byte) (string, bool) {
+	id, ok := strings.CutPrefix(key, pokemonPrefix)
+	if !ok || id == "" || strings.ContainsAny(id, "/?") {
+		return "", false
+	}
+	var pokemon struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(body, &pokemon); err != nil || pokemon.ID <= 0 {
+		return "", false
+	}
+	return pokemonPrefix + strconv.Itoa(pokemon.ID), true
+}
+
+// primaryBaseURL is the pokeapi every URL in the program points at. Cache
+// keys always use it, whichever host actually serves the request.
+const primaryBaseURL = "https://pokeapi.co/api/v2"
+
+// baseURL is the host requests go to first, see Config.BaseURL.
+var baseURL = primaryBaseURL
+
+// primaryAttempts is how many times the primary is tried before falling
+// back to a mirror.
+const primaryAttempts = 2
+
+// mirrors are base URLs serving the same API as primaryBaseURL, tried in
+// order when the primary keeps failing. POKEDEX_MIRRORS sets them as a
+// comma-separated list.
+var mirrors []string
+
+func parseMirrors(list string) []string {
+	parsed := make([]string, 0)
+	for _, mirror := range strings.Split(list, ",") {
+		mirror = strings.TrimSuffix(strings.TrimSpace(mirror), "/")
+		if mirror != "" {
+			parsed = append(parsed, mirror)
+		}
+	}
+	return parsed
+}
+
+// mirrorURL points rawURL at mirror instead of the primary. URLs outside the
+// primary are returned unchanged.
+func mirrorURL(rawURL, mirror string) string {
+	if !strings.HasPrefix(rawURL, primaryBaseURL) {
+		return rawURL
+	}
+	return mirror + strings.TrimPrefix(rawURL, primaryBaseURL)
+}
+
+// retryable reports whether err may go away by asking another host: network
+// failures and server errors, but not e.g. a 404 or a validation failure.
+func retryable(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// fetchRemote requests rawURL from the network, bypassing the cache. If the
+// primary fails primaryAttempts times with a retryable error, each mirror
+// is tried in turn.
+func fetchRemote(rawURL string, validate func([]byte) error) ([]byte, error) {
+	if offline {
+		fmt.Println("Not fetching", rawURL, "while offline")
+		return nil, errOffline
+	}
+	primary := mirrorURL(rawURL, baseURL)
+	var body []byte
+	var err error
+	for attempt := 0; attempt < primaryAttempts; attempt++ {
+		body, err = fetchFrom(primary, validate)
+		if err == nil || !retryable(err) {
+			return body, err
+		}
+	}
+	for _, mirror := range mirrors {
+		target := mirrorURL(rawURL, mirror)
+		if target == rawURL {
+			break
+		}
+		fmt.Println("Trying mirror", mirror)
+		body, err = fetchFrom(target, validate)
+		if err == nil || !retryable(err) {
+			return body, err
+		}
+	}
+	return nil, err
+}
+
+// sendRequest sends req the way every outbound request goes: with userAgent,
+// holding one of requestSlots, after waiting on the rate limiter, and counted
+// in remoteRequests. The slot is held until the response body is closed.
+func sendRequest(req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", userAgent)
+	slots := requestSlots
+	slots.Acquire()
+	limiter.Wait()
+	remoteRequests.Add(1)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		slots.Release()
+		return nil, err
+	}
+	resp.Body = &slotBody{ReadCloser: resp.Body, slots: slots}
+	return resp, nil
+}
+
+// slotBody is a response body that gives its request slot back when closed.
+type slotBody struct {
+	io.ReadCloser
+	slots semaphore
+	once  sync.Once
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.slots.Release)
+	return err
+}
+
+// fetchFrom makes a single request for rawURL.
+func fetchFrom(rawURL string, validate func([]byte) error) ([]byte, error) {
+	if strings.HasPrefix(rawURL, "file://") {
+		body, err := readFixture(rawURL)
+		if err != nil {
+			fmt.Println("Error reading fixture:", err)
+			return nil, err
+		}
+		return body, validateBody(body, validate)
+	}
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		fmt.Println("Error creating request:", err)
+		return nil, err
+	}
+	// Asking for gzip explicitly turns off the transport's transparent
+	// decompression, so readBody handles it.
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := sendRequest(req)
+	if err != nil {
+		fmt.Println("Error fetching data:", err)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Println("Unexpected response:", resp.Status)
+		return nil, &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		fmt.Println("Error reading response:", err)
+		return nil, err
+	}
+	// Check if the content type is JSON
+	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
+		fmt.Println("Response is not JSON:", contentType)
+		return nil, errors.New("response is not JSON")
+	}
+	if err := validateBody(body, validate); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// validateBody runs validate, when non-nil, over body.
+func validateBody(body []byte, validate func([]byte) error) error {
+	if validate == nil {
+		return nil
+	}
+	err := validate(body)
+	if err != nil {
+		fmt.Println("Invalid response:", err)
+	}
+	return err
+}
+
+// readBody returns the response body, decompressing it when the server sent
+// it gzip-encoded.
+func readBody(resp *http.Response) ([]byte, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.ReadAll(resp.Body)
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCanonicalizeURLTrailingSlash(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://pokeapi.co/api/v2/pokemon/25/", "https://pokeapi.co/api/v2/pokemon/25"},
+		{"https://pokeapi.co/api/v2/pokemon/25", "https://pokeapi.co/api/v2/pokemon/25"},
+		{"https://pokeapi.co/api/v2/location-area/?offset=20&limit=20", "https://pokeapi.co/api/v2/location-area?limit=20&offset=20"},
+	}
+	for _, tt := range tests {
+		if got := canonicalizeURL(tt.in); got != tt.want {
+			t.Errorf("canonicalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFetchTrailingSlashSharesEntry(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{
+		"/pokemon/25":  pokemonJSON(25, "pikachu", 112, "electric"),
+		"/pokemon/25/": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+
+	if _, err := fetch("https://pokeapi.co/api/v2/pokemon/25/"); err != nil {
+		t.Fatalf("fetch with slash: %v", err)
+	}
+	if _, err := fetch("https://pokeapi.co/api/v2/pokemon/25"); err != nil {
+		t.Fatalf("fetch without slash: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+	if n := len(pCache.Entries()); n != 1 {
+		t.Errorf("cache holds %d entries, want 1", n)
+	}
+}
+
+func TestFetchFollowsRedirects(t *testing.T) {
+	setupTest(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pokemon/pikachu" {
+			http.Redirect(w, r, "/pokemon/25/", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, pokemonJSON(25, "pikachu", 112, "electric"))
+	}))
+	defer srv.Close()
+	baseURL = srv.URL
+
+	body, err := fetchValid("https://pokeapi.co/api/v2/pokemon/pikachu", validatePokemon)
+	if err != nil {
+		t.Fatalf("fetchValid: %v", err)
+	}
+	if err := validatePokemon(body); err != nil {
+		t.Errorf("redirected body is not a pokemon: %v", err)
+	}
+}
+
+func TestFetchSendsUserAgent(t *testing.T) {
+	for _, ua := range []string{defaultUserAgent, "my-bot/2.0"} {
+		setupTest(t)
+		userAgent = ua
+		var got string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get("User-Agent")
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, pokemonJSON(25, "pikachu", 112, "electric"))
+		}))
+		baseURL = srv.URL
+
+		if _, err := fetch("https://pokeapi.co/api/v2/pokemon/25"); err != nil {
+			t.Errorf("fetch: %v", err)
+		}
+		srv.Close()
+		if got != ua {
+			t.Errorf("server saw User-Agent %q, want %q", got, ua)
+		}
+	}
+}
+
+// stubTransport answers every request itself, recording the URLs asked for.
+type stubTransport struct {
+	body string
+	urls []string
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.urls = append(s.urls, req.URL.String())
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(s.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestSetTransportRoutesRequests(t *testing.T) {
+	setupTest(t)
+	stub := &stubTransport{body: pokemonJSON(25, "pikachu", 112, "electric")}
+	SetTransport(stub)
+
+	body, err := fetchValid("https://pokeapi.co/api/v2/pokemon/25", validatePokemon)
+	if err != nil {
+		t.Fatalf("fetchValid: %v", err)
+	}
+	if string(body) != stub.body {
+		t.Errorf("body = %s, want the stub's", body)
+	}
+	if len(stub.urls) != 1 || stub.urls[0] != "https://pokeapi.co/api/v2/pokemon/25" {
+		t.Errorf("stub saw %v, want one request for pokemon/25", stub.urls)
+	}
+
+	SetTransport(nil)
+	if httpClient.Transport != nil {
+		t.Error("SetTransport(nil) did not restore the default transport")
+	}
+}
+
+func TestFetchDecompressesGzip(t *testing.T) {
+	setupTest(t)
+	want := pokemonJSON(25, "pikachu", 112, "electric")
+	var acceptEncoding string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		io.WriteString(gz, want)
+		gz.Close()
+	}))
+	defer srv.Close()
+	baseURL = srv.URL
+
+	body, err := fetchValid("https://pokeapi.co/api/v2/pokemon/25", validatePokemon)
+	if err != nil {
+		t.Fatalf("fetchValid: %v", err)
+	}
+	if string(body) != want {
+		t.Errorf("body = %q, want the decompressed JSON", body)
+	}
+	if acceptEncoding != "gzip" {
+		t.Errorf("Accept-Encoding = %q, want gzip", acceptEncoding)
+	}
+	cached, err := pCache.Get("https://pokeapi.co/api/v2/pokemon/25")
+	if err != nil || string(cached) != want {
+		t.Errorf("cached %q, %v, want the decompressed JSON", cached, err)
+	}
+}
+
+func TestTTLFor(t *testing.T) {
+	tests := []struct {
+		key  string
+		want time.Duration
+	}{
+		{primaryBaseURL + "/pokemon/25", pokemonTTL},
+		{primaryBaseURL + "/pokemon/pikachu", pokemonTTL},
+		{primaryBaseURL + "/pokemon?limit=100000", 0},
+		{primaryBaseURL + "/pokemon/", 0},
+		{primaryBaseURL + "/location-area", locationListTTL},
+		{primaryBaseURL + "/location-area?limit=20&offset=20", locationListTTL},
+		{primaryBaseURL + "/location-area/canalave-city-area", 0},
+		{primaryBaseURL + "/type/fire", 0},
+	}
+	for _, tt := range tests {
+		if got := ttlFor(tt.key); got != tt.want {
+			t.Errorf("ttlFor(%q) = %s, want %s", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCachedPokemonOutlivesDefaultTTL(t *testing.T) {
+	clock := setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/25":                       pokemonJSON(25, "pikachu", 112, "electric"),
+		"/location-area/canalave-city-area": `{"id":1,"name":"canalave-city-area","pokemon_encounters":[]}`,
+	})
+	fetch(primaryBaseURL + "/pokemon/25")
+	fetch(primaryBaseURL + "/location-area/canalave-city-area")
+
+	clock.Advance(time.Hour)
+	pCache.Reap()
+	if !pCache.Contains(primaryBaseURL + "/pokemon/25") {
+		t.Error("pokemon expired after an hour")
+	}
+	if pCache.Contains(primaryBaseURL + "/location-area/canalave-city-area") {
+		t.Error("location area outlived the default TTL")
+	}
+}
+
+func TestFetchReorderedQuerySharesEntry(t *testing.T) {
+	setupTest(t)
+	page := listJSON(40, "", "pastoria-city-area")
+	requests := stubAPI(t, map[string]string{
+		"/location-area?offset=20&limit=20": page,
+		"/location-area?limit=20&offset=20": page,
+	})
+	for _, url := range []string{
+		primaryBaseURL + "/location-area?offset=20&limit=20",
+		primaryBaseURL + "/location-area?limit=20&offset=20",
+		primaryBaseURL + "/location-area/?limit=20&offset=20",
+	} {
+		body, err := fetchValid(url, validateList)
+		if err != nil || string(body) != page {
+			t.Errorf("fetchValid(%q) = %s, %v", url, body, err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+	if n := len(pCache.Entries()); n != 1 {
+		t.Errorf("cache holds %d entries, want 1", n)
+	}
+}
+
+func TestParseMirrors(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{"", []string{}},
+		{"https://a.example/api/v2", []string{"https://a.example/api/v2"}},
+		{" https://a.example/api/v2/ ,, https://b.example/api/v2", []string{"https://a.example/api/v2", "https://b.example/api/v2"}},
+	}
+	for _, tt := range tests {
+		if got := parseMirrors(tt.list); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseMirrors(%q) = %q, want %q", tt.list, got, tt.want)
+		}
+	}
+}
+
+// stubHost serves every request with status and body, counting requests.
+func stubHost(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestFetchFailsOverToMirror(t *testing.T) {
+	setupTest(t)
+	primary, primaryRequests := stubHost(t, http.StatusServiceUnavailable, "down")
+	mirror, mirrorRequests := stubHost(t, http.StatusOK, pokemonJSON(25, "pikachu", 112, "electric"))
+	baseURL = primary.URL
+	mirrors = []string{mirror.URL}
+
+	var body []byte
+	var err error
+	out := captureOutput(t, func() {
+		body, err = fetchValid(primaryBaseURL+"/pokemon/pikachu", validatePokemon)
+	})
+	if err != nil || validatePokemon(body) != nil {
+		t.Fatalf("fetch = %s, %v, want the mirror's pokemon", body, err)
+	}
+	if n := primaryRequests.Load(); n != primaryAttempts {
+		t.Errorf("primary was tried %d times, want %d", n, primaryAttempts)
+	}
+	if n := mirrorRequests.Load(); n != 1 {
+		t.Errorf("mirror was tried %d times, want 1", n)
+	}
+	if !strings.Contains(out, "Trying mirror "+mirror.URL) {
+		t.Errorf("output does not mention the mirror:\n%s", out)
+	}
+}
+
+func TestFetchDoesNotFailOverOnNotFound(t *testing.T) {
+	setupTest(t)
+	primary, primaryRequests := stubHost(t, http.StatusNotFound, "Not Found")
+	mirror, mirrorRequests := stubHost(t, http.StatusOK, pokemonJSON(25, "pikachu", 112, "electric"))
+	baseURL = primary.URL
+	mirrors = []string{mirror.URL}
+
+	captureOutput(t, func() {
+		if _, err := fetch(primaryBaseURL + "/pokemon/nope"); !isNotFound(err) {
+			t.Errorf("fetch = %v, want a 404", err)
+		}
+	})
+	if n := primaryRequests.Load(); n != 1 {
+		t.Errorf("primary was tried %d times, want 1", n)
+	}
+	if n := mirrorRequests.Load(); n != 0 {
+		t.Errorf("mirror was tried %d times after a 404", n)
+	}
+}
+
+func TestFetchByNameThenIDSharesEntry(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{
+		"/pokemon/pikachu": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	for _, url := range []string{primaryBaseURL + "/pokemon/pikachu", primaryBaseURL + "/pokemon/25"} {
+		body, err := fetchValid(url, validatePokemon)
+		if err != nil || validatePokemon(body) != nil {
+			t.Errorf("fetchValid(%q) = %s, %v", url, body, err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+	entries := pCache.Entries()
+	if len(entries) != 1 || entries[0].Key != primaryBaseURL+"/pokemon/25" {
+		t.Errorf("cache holds %+v, want one entry under the id URL", entries)
+	}
+}
+
+func TestRequestSlotsCapConcurrency(t *testing.T) {
+	setupTest(t)
+	const slots, fetches = 2, 10
+	requestSlots = newSemaphore(slots)
+	var inFlight, maxInFlight atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"count":0,"results":[]}`)
+	}))
+	t.Cleanup(srv.Close)
+	baseURL = srv.URL
+
+	var wg sync.WaitGroup
+	for i := 0; i < fetches; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := fetch(fmt.Sprintf("%s/location-area/%d", primaryBaseURL, i)); err != nil {
+				t.Errorf("fetch %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := maxInFlight.Load(); n > slots {
+		t.Errorf("%d requests were in flight at once, want at most %d", n, slots)
+	}
+	if n := len(pCache.Entries()); n != fetches {
+		t.Errorf("cached %d responses, want %d", n, fetches)
+	}
+}
+
+func TestSendRequestHoldsSlotUntilBodyClosed(t *testing.T) {
+	setupTest(t)
+	requestSlots = newSemaphore(1)
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	sent := remoteRequests.Load()
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := sendRequest(req)
+	if err != nil {
+		t.Fatalf("sendRequest: %v", err)
+	}
+	if len(requestSlots) != 1 {
+		t.Errorf("%d slots held before the body is closed, want 1", len(requestSlots))
+	}
+	resp.Body.Close()
+	resp.Body.Close()
+	if len(requestSlots) != 0 {
+		t.Errorf("%d slots held after the body is closed, want 0", len(requestSlots))
+	}
+	if got != userAgent || remoteRequests.Load()-sent != 1 {
+		t.Errorf("server saw User-Agent %q after %d requests", got, remoteRequests.Load()-sent)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fixtureDir is the directory file:// URLs may be read from, set when the
+// base URL is a file:// URL. Reads outside of it are refused.
+var fixtureDir string
+
+var errOutsideFixtures = errors.New("file is outside the fixture directory")
+
+// readFixture returns the JSON fixture rawURL points at. "file:///dir/pokemon/
+// pikachu" is served from /dir/pokemon/pikachu, /dir/pokemon/pikachu.json or
+// /dir/pokemon/pikachu/index.json, whichever exists first. A URL with a query
+// string is served from a file named after the query in that directory, e.g.
+// "location-area?offset=20&limit=20" from /dir/location-area/offset=20&limit=20.json,
+// or with the parameters sorted by key (limit=20&offset=20.json). A missing
+// fixture is reported like a 404.
+func readFixture(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if fixtureDir == "" {
+		return nil, errOutsideFixtures
+	}
+	path := filepath.Clean(u.Path)
+	rel, err := filepath.Rel(fixtureDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, errOutsideFixtures
+	}
+	for _, candidate := range fixtureCandidates(path, u) {
+		info, err := os.Stat(candidate)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		return os.ReadFile(candidate)
+	}
+	return nil, &statusError{code: http.StatusNotFound, status: fmt.Sprintf("%d %s", http.StatusNotFound, http.StatusText(http.StatusNotFound))}
+}
+
+// fixtureCandidates lists the files that may hold the fixture of u, whose
+// cleaned path is path, in the order readFixture tries them.
+func fixtureCandidates(path string, u *url.URL) []string {
+	if u.RawQuery == "" {
+		return []string{path, path + ".json", filepath.Join(path, "index.json")}
+	}
+	candidates := make([]string, 0, 2)
+	// A raw query holding a separator could climb out of path.
+	if !strings.ContainsAny(u.RawQuery, `/\`) {
+		candidates = append(candidates, filepath.Join(path, u.RawQuery+".json"))
+	}
+	// Encode sorts the parameters and escapes separators.
+	if sorted := u.Query().Encode(); sorted != u.RawQuery {
+		candidates = append(candidates, filepath.Join(path, sorted+".json"))
+	}
+	return candidates
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFixtures creates each file of files, relative to a new fixture
+// directory, points the base URL at it and returns it.
+func writeFixtures(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	baseURL = "file://" + dir
+	fixtureDir = dir
+	return dir
+}
+
+func TestFetchFromFixtures(t *testing.T) {
+	setupTest(t)
+	writeFixtures(t, map[string]string{
+		"pokemon/pikachu":                       pokemonJSON(25, "pikachu", 112, "electric"),
+		"pokemon/eevee.json":                    pokemonJSON(133, "eevee", 65, "normal"),
+		"pokemon/abra/index.json":               pokemonJSON(63, "abra", 62, "psychic"),
+		"location-area/offset=20&limit=20.json": listJSON(40, "", "raw-order"),
+		"location-area/limit=20&offset=40.json": listJSON(60, "", "sorted-order"),
+		"location-area/index.json":              listJSON(60, "", "first-page"),
+	})
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/pokemon/pikachu", "pikachu"},
+		{"/pokemon/eevee", "eevee"},
+		{"/pokemon/abra/", "abra"},
+		{"/location-area?offset=20&limit=20", "raw-order"},
+		{"/location-area?offset=40&limit=20", "sorted-order"},
+		{"/location-area", "first-page"},
+	}
+	for _, tt := range tests {
+		body, err := fetch(primaryBaseURL + tt.path)
+		if err != nil {
+			t.Errorf("fetch(%s): %v", tt.path, err)
+			continue
+		}
+		if !strings.Contains(string(body), `"`+tt.want+`"`) {
+			t.Errorf("fetch(%s) = %s, want the %s fixture", tt.path, body, tt.want)
+		}
+	}
+
+	captureOutput(t, func() {
+		if _, err := fetch(primaryBaseURL + "/pokemon/missingno"); !isNotFound(err) {
+			t.Errorf("fetching a missing fixture = %v, want a 404", err)
+		}
+	})
+}
+
+func TestReadFixtureStaysInDirectory(t *testing.T) {
+	setupTest(t)
+	dir := writeFixtures(t, map[string]string{"pokemon/pikachu": "{}"})
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dir), "secret.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, rawURL := range []string{
+		"file://" + dir + "/../secret.json",
+		"file://" + dir + "/pokemon?a=../../secret",
+		"file:///etc/passwd",
+	} {
+		if _, err := readFixture(rawURL); err == nil {
+			t.Errorf("readFixture(%q) read outside the fixtures", rawURL)
+		}
+	}
+	if _, err := readFixture("file://" + dir + "/../secret.json"); !errors.Is(err, errOutsideFixtures) {
+		t.Errorf("readFixture of a parent file = %v, want errOutsideFixtures", err)
+	}
+
+	fixtureDir = ""
+	if _, err := readFixture("file://" + dir + "/pokemon/pikachu"); !errors.Is(err, errOutsideFixtures) {
+		t.Errorf("readFixture without a fixture directory = %v, want errOutsideFixtures", err)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "strings"
+
+// parseFlags splits params into positional arguments and "--name" flags.
+func parseFlags(params []string) ([]string, map[string]bool) {
+	args := make([]string, 0, len(params))
+	flags := make(map[string]bool)
+	for _, param := range params {
+		if strings.HasPrefix(param, "--") {
+			flags[strings.TrimPrefix(param, "--")] = true
+			continue
+		}
+		args = append(args, param)
+	}
+	return args, flags
+}
+
+// takeFlagValue removes "--name value" (or "--name=value") from params and
+// returns the value along with the remaining params. The value is empty when
+// the flag is absent.
+func takeFlagValue(params []string, name string) (string, []string) {
+	flag := "--" + name
+	rest := make([]string, 0, len(params))
+	value := ""
+	for i := 0; i < len(params); i++ {
+		switch {
+		case params[i] == flag && i+1 < len(params):
+			value = params[i+1]
+			i++
+		case strings.HasPrefix(params[i], flag+"="):
+			value = strings.TrimPrefix(params[i], flag+"=")
+		default:
+			rest = append(rest, params[i])
+		}
+	}
+	return value, rest
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxHistory bounds how many lines are kept in memory and on disk.
+const maxHistory = 500
+
+// history holds previously entered lines and a cursor used for up/down
+// recall. The cursor sits at len(lines) when no line is being recalled.
+type history struct {
+	lines []string
+	pos   int
+	path  string
+}
+
+// loadHistory reads the history file at path. A missing file yields an
+// empty history.
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			h.lines = append(h.lines, scanner.Text())
+		}
+	}
+	h.trim()
+	h.pos = len(h.lines)
+	return h
+}
+
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pokedex_history")
+}
+
+// Add records line, skipping blanks and immediate repeats, and resets the
+// cursor.
+func (h *history) Add(line string) {
+	line = strings.TrimSpace(line)
+	if line != "" && (len(h.lines) == 0 || h.lines[len(h.lines)-1] != line) {
+		h.lines = append(h.lines, line)
+		h.trim()
+	}
+	h.pos = len(h.lines)
+}
+
+// Prev moves the cursor to the previous line and returns it. At the oldest
+// line it keeps returning that line.
+func (h *history) Prev() (string, bool) {
+	if len(h.lines) == 0 {
+		return "", false
+	}
+	if h.pos > 0 {
+		h.pos--
+	}
+	return h.lines[h.pos], true
+}
+
+// Next moves the cursor to the next line and returns it. Moving past the
+// newest line returns an empty line.
+func (h *history) Next() (string, bool) {
+	if h.pos >= len(h.lines) {
+		return "", false
+	}
+	h.pos++
+	if h.pos == len(h.lines) {
+		return "", true
+	}
+	return h.lines[h.pos], true
+}
+
+func (h *history) trim() {
+	if len(h.lines) > maxHistory {
+		h.lines = h.lines[len(h.lines)-maxHistory:]
+	}
+}
+
+// Save writes the history to its dotfile.
+func (h *history) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	return os.WriteFile(h.path, []byte(strings.Join(h.lines, "\n")+"\n"), 0600)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryNavigation(t *testing.T) {
+	h := &history{}
+	if _, ok := h.Prev(); ok {
+		t.Error("Prev on an empty history recalled a line")
+	}
+	for _, line := range []string{"map", "explore canalave-city-area", "catch pikachu"} {
+		h.Add(line)
+	}
+
+	steps := []struct {
+		up     bool
+		want   string
+		wantOk bool
+	}{
+		{true, "catch pikachu", true},
+		{true, "explore canalave-city-area", true},
+		{true, "map", true},
+		{true, "map", true}, // stays on the oldest line
+		{false, "explore canalave-city-area", true},
+		{false, "catch pikachu", true},
+		{false, "", true}, // back to an empty line
+		{false, "", false},
+	}
+	for i, step := range steps {
+		var got string
+		var ok bool
+		if step.up {
+			got, ok = h.Prev()
+		} else {
+			got, ok = h.Next()
+		}
+		if got != step.want || ok != step.wantOk {
+			t.Errorf("step %d: got %q, %v, want %q, %v", i, got, ok, step.want, step.wantOk)
+		}
+	}
+}
+
+func TestHistoryAddResetsCursor(t *testing.T) {
+	h := &history{}
+	h.Add("map")
+	h.Add("mapb")
+	h.Prev()
+	h.Prev()
+	h.Add("help")
+	if got, _ := h.Prev(); got != "help" {
+		t.Errorf("Prev after Add = %q, want help", got)
+	}
+}
+
+func TestHistorySkipsBlanksAndRepeats(t *testing.T) {
+	h := &history{}
+	for _, line := range []string{"map", "  ", "map", " map ", "help", "map"} {
+		h.Add(line)
+	}
+	want := []string{"map", "help", "map"}
+	if fmt.Sprint(h.lines) != fmt.Sprint(want) {
+		t.Errorf("lines = %q, want %q", h.lines, want)
+	}
+}
+
+func TestHistorySaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h := loadHistory(path)
+	for i := 0; i < maxHistory+5; i++ {
+		h.Add(fmt.Sprint("catch ", i))
+	}
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := loadHistory(path)
+	if len(loaded.lines) != maxHistory {
+		t.Fatalf("loaded %d lines, want %d", len(loaded.lines), maxHistory)
+	}
+	if got, _ := loaded.Prev(); got != fmt.Sprint("catch ", maxHistory+4) {
+		t.Errorf("newest line = %q", got)
+	}
+	if loaded.lines[0] != "catch 5" {
+		t.Errorf("oldest line = %q, want catch 5", loaded.lines[0])
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type PokemonSpecies struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	IsLegendary bool   `json:"is_legendary"`
+	IsMythical  bool   `json:"is_mythical"`
+	Generation  struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"generation"`
+	EvolutionChain struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	// Varieties are the pokemon of the species, e.g. giratina-altered and
+	// giratina-origin. The default one is not always named after it.
+	Varieties []struct {
+		IsDefault bool          `json:"is_default"`
+		Pokemon   namedResource `json:"pokemon"`
+	} `json:"varieties"`
+}
+
+// fetchSpecies returns the species of p, fetched through the cache.
+func fetchSpecies(p Pokemon) (PokemonSpecies, error) {
+	return fetchSpeciesAt(p.Species.URL)
+}
+
+// fetchSpeciesAt returns the species at url, fetched through the cache.
+func fetchSpeciesAt(url string) (PokemonSpecies, error) {
+	var species PokemonSpecies
+	if url == "" {
+		return species, errors.New("no species URL")
+	}
+	body, err := fetch(url)
+	if err != nil {
+		return species, err
+	}
+	err = json.Unmarshal(body, &species)
+	return species, err
+}
+
+// defaultVariety returns the URL of the species' default pokemon.
+func (s PokemonSpecies) defaultVariety() (string, error) {
+	for _, variety := range s.Varieties {
+		if variety.IsDefault {
+			return variety.Pokemon.URL, nil
+		}
+	}
+	return "", fmt.Errorf("%s has no default variety", s.Name)
+}
+
+// generation returns the name of the generation p debuted in, or "unknown"
+// when the species cannot be fetched.
+func generation(p Pokemon) string {
+	species, err := fetchSpecies(p)
+	if err != nil || species.Generation.Name == "" {
+		return "unknown"
+	}
+	return species.Generation.Name
+}
+
+// knownLegendaries is used when the species endpoint cannot be reached.
+var knownLegendaries = map[string]bool{
+	"articuno": true, "zapdos": true, "moltres": true, "mewtwo": true,
+	"raikou": true, "entei": true, "suicune": true, "lugia": true, "ho-oh": true,
+	"regirock": true, "regice": true, "registeel": true, "latias": true,
+	"latios": true, "kyogre": true, "groudon": true, "rayquaza": true,
+	"uxie": true, "mesprit": true, "azelf": true, "dialga": true, "palkia": true,
+	"heatran": true, "regigigas": true, "giratina": true, "cresselia": true,
+	"cobalion": true, "terrakion": true, "virizion": true, "tornadus": true,
+	"thundurus": true, "reshiram": true, "zekrom": true, "landorus": true,
+	"kyurem": true, "xerneas": true, "yveltal": true, "zygarde": true,
+	"type-null": true, "silvally": true, "tapu-koko": true, "tapu-lele": true,
+	"tapu-bulu": true, "tapu-fini": true, "cosmog": true, "cosmoem": true,
+	"solgaleo": true, "lunala": true, "necrozma": true, "zacian": true,
+	"zamazenta": true, "eternatus": true, "kubfu": true, "urshifu": true,
+	"regieleki": true, "regidrago": true, "glastrier": true, "spectrier": true,
+	"calyrex": true, "enamorus": true, "wo-chien": true, "chien-pao": true,
+	"ting-lu": true, "chi-yu": true, "koraidon": true, "miraidon": true,
+	"okidogi": true, "munkidori": true, "fezandipiti": true, "ogerpon": true,
+	"terapagos": true,
+}
+
+// isLegendary reports whether p is a legendary pokemon. The species
+// endpoint's is_legendary flag is authoritative; knownLegendaries is only
+// consulted when the species cannot be fetched. It is keyed by species, so
+// forms such as giratina-altered or zygarde-50 are found too.
+func isLegendary(p Pokemon) bool {
+	species, err := fetchSpecies(p)
+	if err == nil {
+		return species.IsLegendary
+	}
+	name := p.Species.Name
+	if name == "" {
+		name = p.Name
+	}
+	return knownLegendaries[name]
+}
+
+func listLegendaries(limit int) error {
+	names := make([]string, 0)
+	for _, name := range pDex.Names() {
+		pokemon, err := pDex.GetPokemon(name)
+		if err != nil {
+			fmt.Println("Error unmarshalling JSON:", err)
+			return err
+		}
+		if isLegendary(pokemon) {
+			names = append(names, name)
+		}
+	}
+
+	fmt.Println("Legendary pokemon caught:")
+	if len(names) == 0 {
+		fmt.Println("  none yet")
+	}
+	printList(names, limit)
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func speciesJSON(id int, name string, legendary bool, generation string) string {
+	data, err := json.Marshal(map[string]any{
+		"id":           id,
+		"name":         name,
+		"is_legendary": legendary,
+		"generation":   map[string]string{"name": generation},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func TestIsLegendary(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon-species/150": speciesJSON(150, "mewtwo", true, "generation-i"),
+		"/pokemon-species/25":  speciesJSON(25, "pikachu", false, "generation-i"),
+	})
+	tests := []struct {
+		body    string
+		species string
+		want    bool
+	}{
+		{pokemonJSON(150, "mewtwo", 340, "psychic"), "", true},
+		{pokemonJSON(25, "pikachu", 112, "electric"), "", false},
+		// No species response: the known list decides.
+		{pokemonJSON(144, "articuno", 290, "ice", "flying"), "", true},
+		{pokemonJSON(1, "bulbasaur", 64, "grass"), "", false},
+		{pokemonJSON(10007, "giratina-altered", 340, "ghost", "dragon"), "giratina", true},
+		{pokemonJSON(641, "tornadus-incarnate", 290, "flying"), "tornadus", true},
+	}
+	for _, tt := range tests {
+		var pokemon Pokemon
+		if err := json.Unmarshal([]byte(tt.body), &pokemon); err != nil {
+			t.Fatal(err)
+		}
+		if tt.species != "" {
+			pokemon.Species.Name = tt.species
+		}
+		if got := isLegendary(pokemon); got != tt.want {
+			t.Errorf("isLegendary(%s) = %v, want %v", pokemon.Name, got, tt.want)
+		}
+	}
+}
+
+func TestListLegendaries(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon-species/150": speciesJSON(150, "mewtwo", true, "generation-i"),
+		"/pokemon-species/25":  speciesJSON(25, "pikachu", false, "generation-i"),
+	})
+	catchPokemon(t, pokemonJSON(150, "mewtwo", 340, "psychic"))
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+
+	out := captureOutput(t, func() {
+		if err := commandPokedex("legendaries"); err != nil {
+			t.Errorf("pokedex legendaries: %v", err)
+		}
+	})
+	if !strings.Contains(out, "- mewtwo") {
+		t.Errorf("output does not list mewtwo:\n%s", out)
+	}
+	if strings.Contains(out, "pikachu") {
+		t.Errorf("output lists pikachu:\n%s", out)
+	}
+}
+
+func TestInspectShowsGeneration(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon-species/387": speciesJSON(387, "turtwig", false, "generation-iv"),
+	})
+	catchPokemon(t, pokemonJSON(387, "turtwig", 64, "grass"))
+	catchPokemon(t, pokemonJSON(390, "chimchar", 62, "fire"))
+
+	for name, want := range map[string]string{
+		"turtwig":  "Generation: generation-iv",
+		"chimchar": "Generation: unknown", // the species request fails
+	} {
+		out := captureOutput(t, func() {
+			if err := commandInspect(name, "--gen"); err != nil {
+				t.Errorf("inspect %s --gen: %v", name, err)
+			}
+		})
+		if !strings.Contains(out, want) {
+			t.Errorf("inspect %s --gen output lacks %q:\n%s", name, want, out)
+		}
+	}
+
+	out := captureOutput(t, func() {
+		commandInspect("turtwig")
+	})
+	if strings.Contains(out, "Generation:") {
+		t.Errorf("inspect without --gen shows the generation:\n%s", out)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// lineEditor reads REPL input. On a terminal it supports backspace and
+// up/down history recall; otherwise it falls back to plain line reads.
+type lineEditor struct {
+	in       *bufio.Reader
+	history  *history
+	terminal bool
+}
+
+func newLineEditor(h *history) *lineEditor {
+	return &lineEditor{
+		in:       bufio.NewReader(os.Stdin),
+		history:  h,
+		terminal: isTerminal(os.Stdin),
+	}
+}
+
+// interactive reports whether a person is typing at a terminal, as opposed
+// to commands being piped in.
+func interactive() bool {
+	return pEditor != nil && pEditor.terminal
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ReadLine prints prompt and returns the next line without its newline.
+// Up and down recall lines from the history; recording the line is left to
+// the caller. Ctrl-C discards the line typed so far and returns an empty
+// one. When stdin is not a terminal (e.g. piped commands) the prompt is not
+// printed.
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	if !e.terminal {
+		return e.readPlain()
+	}
+	fmt.Print(prompt)
+
+	restore, err := enterCbreak()
+	if err != nil {
+		return e.readPlain()
+	}
+	defer restore()
+
+	line := []rune{}
+	redraw := func() {
+		fmt.Print("\r\033[K", prompt, string(line))
+	}
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\n', '\r':
+			fmt.Println()
+			return string(line), nil
+		case 127, '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+		case 3: // Ctrl-C, delivered as a byte while signals are off
+			fmt.Println("^C")
+			return "", nil
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Println()
+				return "", io.EOF
+			}
+		case 27: // escape sequence, arrows are ESC [ A/B
+			if next, _, _ := e.in.ReadRune(); next != '[' {
+				continue
+			}
+			arrow, _, _ := e.in.ReadRune()
+			var recalled string
+			var ok bool
+			switch arrow {
+			case 'A':
+				recalled, ok = e.history.Prev()
+			case 'B':
+				recalled, ok = e.history.Next()
+			}
+			if ok {
+				line = []rune(recalled)
+				redraw()
+			}
+		default:
+			if r >= ' ' {
+				line = append(line, r)
+				fmt.Print(string(r))
+			}
+		}
+	}
+}
+
+func (e *lineEditor) readPlain() (string, error) {
+	input, err := e.in.ReadString('\n')
+	if err != nil && (err != io.EOF || input == "") {
+		return "", err
+	}
+	return strings.TrimRight(input, "\r\n"), nil
+}
+
+// enterCbreak switches the terminal to unbuffered, no-echo input and returns
+// a function restoring the previous settings. Signal keys are turned off too,
+// so Ctrl-C reaches ReadLine as a byte and cancels the line instead of
+// killing the process with echo still off.
+func enterCbreak() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	_, err = stty("-icanon", "-echo", "-isig", "min", "1")
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// pEditor reads the REPL input. Commands use it to ask follow-up questions.
+var pEditor *lineEditor
+
+// confirm asks a yes/no question and reports whether the answer was yes.
+// Unless a person is typing at a terminal it answers no without reading
+// anything, so piped commands are never taken as the answer.
+func confirm(question string) bool {
+	if !interactive() {
+		return false
+	}
+	answer, err := pEditor.ReadLine(question + " [y/N] ")
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// pipedEditor reads input as if it had been piped into the program.
+func pipedEditor(input string) *lineEditor {
+	return &lineEditor{in: bufio.NewReader(strings.NewReader(input)), history: &history{}}
+}
+
+func TestConfirmIgnoresPipedInput(t *testing.T) {
+	setupTest(t)
+	pEditor = pipedEditor("y\nhelp\n")
+	if confirm("Reset?") {
+		t.Error("a piped line was taken as the answer")
+	}
+	if line, err := pEditor.ReadLine("> "); line != "y" || err != nil {
+		t.Errorf("next line = %q, %v, want the unread \"y\"", line, err)
+	}
+}
+
+func TestREPLWithPipedInput(t *testing.T) {
+	setupTest(t)
+	editor := pipedEditor("dicesides 12\n\nbogus\nmovelimit 3")
+	var lastErr, readErr error
+	out := captureOutput(t, func() {
+		lastErr, readErr = runREPL(editor)
+	})
+	if readErr != nil {
+		t.Errorf("runREPL read error: %v", readErr)
+	}
+	if !errors.Is(lastErr, errUnknownCommand) {
+		t.Errorf("runREPL = %v, want the unknown command's error", lastErr)
+	}
+	if strings.Contains(out, "Pokedex>") {
+		t.Errorf("printed a prompt for piped input:\n%s", out)
+	}
+	if diceSides != 12 || moveLimit != 3 {
+		t.Errorf("diceSides = %d, moveLimit = %d, want every line run", diceSides, moveLimit)
+	}
+	if len(editor.history.lines) != 0 {
+		t.Errorf("piped lines went into the history: %q", editor.history.lines)
+	}
+}
+
+func TestREPLEndsOnTerminalEOF(t *testing.T) {
+	setupTest(t)
+	// Ctrl-D makes ReadLine return io.EOF, as the end of this input does.
+	editor := pipedEditor("dicesides 12\n")
+	editor.terminal = true
+	var lastErr, readErr error
+	captureOutput(t, func() {
+		lastErr, readErr = runREPL(editor)
+	})
+	if lastErr != nil || readErr != nil {
+		t.Errorf("runREPL = %v, %v, want a normal exit", lastErr, readErr)
+	}
+	if diceSides != 12 {
+		t.Errorf("diceSides = %d, want the line before EOF run", diceSides)
+	}
+}
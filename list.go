@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// maxResults caps how many lines a list-printing command shows, unless the
+// command has its own setting (see movelimit). A command given --limit <n>
+// uses n instead.
+var maxResults = 20
+
+var errInvalidLimit = errors.New("invalid results limit")
+
+// printList prints at most limit names, followed by a "(showing X of Y)"
+// footer when some were left out.
+func printList(names []string, limit int) {
+	rows := make([]string, len(names))
+	for i, name := range names {
+		rows[i] = "  - " + name
+	}
+	printRows(rows, limit)
+}
+
+// printRows is printList for rows laid out by the caller, such as table
+// lines, which are printed as they are.
+func printRows(rows []string, limit int) {
+	for i, row := range rows {
+		if i == limit {
+			fmt.Printf("  (showing %d of %d)\n", limit, len(rows))
+			break
+		}
+		fmt.Println(row)
+	}
+}
+
+// takeLimit removes "--limit <n>" from params and returns n, or fallback
+// when the flag is absent.
+func takeLimit(params []string, fallback int) (int, []string, error) {
+	raw, params := takeFlagValue(params, "limit")
+	if raw == "" {
+		return fallback, params, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		fmt.Println("Please provide a positive number for --limit")
+		return 0, params, errInvalidLimit
+	}
+	return n, params, nil
+}
+
+func commandMaxResults(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Max results:", maxResults)
+		return nil
+	}
+	n, err := strconv.Atoi(params[0])
+	if err != nil || n < 1 {
+		fmt.Println("Please provide a positive number")
+		return errInvalidLimit
+	}
+	maxResults = n
+	fmt.Println("Max results set to", maxResults)
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrintList(t *testing.T) {
+	names := []string{"bulbasaur", "charmander", "squirtle"}
+	tests := []struct {
+		limit int
+		want  string
+	}{
+		{1, "  - bulbasaur\n  (showing 1 of 3)\n"},
+		{2, "  - bulbasaur\n  - charmander\n  (showing 2 of 3)\n"},
+		{3, "  - bulbasaur\n  - charmander\n  - squirtle\n"},
+		{10, "  - bulbasaur\n  - charmander\n  - squirtle\n"},
+	}
+	for _, tt := range tests {
+		if got := captureOutput(t, func() { printList(names, tt.limit) }); got != tt.want {
+			t.Errorf("printList(limit %d) printed %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+	if got := captureOutput(t, func() { printList(nil, 5) }); got != "" {
+		t.Errorf("printList of nothing printed %q", got)
+	}
+}
+
+func TestTakeLimit(t *testing.T) {
+	tests := []struct {
+		params    []string
+		limit     int
+		remaining []string
+		err       error
+	}{
+		{[]string{"pi"}, 20, []string{"pi"}, nil},
+		{[]string{"pi", "--limit", "5"}, 5, []string{"pi"}, nil},
+		{[]string{"--limit", "3", "recent"}, 3, []string{"recent"}, nil},
+		{[]string{"pi", "--limit", "0"}, 0, []string{"pi"}, errInvalidLimit},
+		{[]string{"pi", "--limit", "many"}, 0, []string{"pi"}, errInvalidLimit},
+	}
+	for _, tt := range tests {
+		var limit int
+		var remaining []string
+		var err error
+		captureOutput(t, func() {
+			limit, remaining, err = takeLimit(tt.params, 20)
+		})
+		if limit != tt.limit || !reflect.DeepEqual(remaining, tt.remaining) || !errors.Is(err, tt.err) {
+			t.Errorf("takeLimit(%q) = %d, %q, %v, want %d, %q, %v",
+				tt.params, limit, remaining, err, tt.limit, tt.remaining, tt.err)
+		}
+	}
+}
+
+func TestMaxResultsLimitsLists(t *testing.T) {
+	setupTest(t)
+	for i, name := range []string{"bulbasaur", "charmander", "squirtle", "pikachu"} {
+		catchPokemon(t, pokemonJSON(i+1, name, 50, "normal"))
+	}
+	captureOutput(t, func() {
+		if err := commandMaxResults("2"); err != nil {
+			t.Errorf("maxresults 2: %v", err)
+		}
+		if err := commandMaxResults("0"); !errors.Is(err, errInvalidLimit) {
+			t.Errorf("maxresults 0 = %v, want errInvalidLimit", err)
+		}
+	})
+	if maxResults != 2 {
+		t.Fatalf("maxResults = %d, want 2", maxResults)
+	}
+
+	out := captureOutput(t, func() { commandPokedex() })
+	if strings.Count(out, "  - ") != 2 || !strings.Contains(out, "(showing 2 of 4)") {
+		t.Errorf("pokedex with maxresults 2 printed:\n%s", out)
+	}
+	out = captureOutput(t, func() { commandPokedex("--limit", "3") })
+	if strings.Count(out, "  - ") != 3 || !strings.Contains(out, "(showing 3 of 4)") {
+		t.Errorf("pokedex --limit 3 printed:\n%s", out)
+	}
+}
+
+func TestListCommandsTakeLimit(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/location-area/canalave-city-area": locationAreaJSON("canalave-city-area", "tentacool", "staryu", "wingull"),
+	})
+	for _, name := range []string{"pikachu", "eevee", "snorlax"} {
+		pCatchLog.Record(catchAttempt{name: name, at: pDex.clock.Now()})
+	}
+	pCache.Add(primaryBaseURL+"/type/fire", []byte(`{}`))
+	maxResults = 2
+
+	tests := []struct {
+		command func(...string) error
+		params  []string
+		want    string
+	}{
+		{commandExplore, []string{"canalave-city-area"}, "(showing 2 of 3)"},
+		{commandExplore, []string{"canalave-city-area", "--limit", "1"}, "(showing 1 of 3)"},
+		{commandCatchLog, nil, "(showing 2 of 3)"},
+		{commandCatchLog, []string{"--limit", "1"}, "(showing 1 of 3)"},
+		{commandCacheDump, []string{"--limit", "1"}, "(showing 1 of 2)\nTotal: "},
+	}
+	for _, tt := range tests {
+		out := captureOutput(t, func() {
+			if err := tt.command(tt.params...); err != nil {
+				t.Errorf("%q: %v", tt.params, err)
+			}
+		})
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("%q printed:\n%s\nwant %q", tt.params, out, tt.want)
+		}
+	}
+}
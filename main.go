@@ -1,17 +1,19 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math/rand"
-	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ablanchetMD/pokedex/pokecache"
@@ -19,25 +21,41 @@ import (
 
 type cliCommand struct {
 	name        string
+	category    string
 	description string
-	callback    func(params ...string) error
+	// needsNetwork commands can never be served from the cache, so they are
+	// refused while offline mode is on. Other commands still run offline and
+	// only fail on the requests the cache cannot answer.
+	needsNetwork bool
+	callback     func(params ...string) error
 }
 
+// categories lists the help sections in display order.
+var categories = []string{"exploration", "collection", "utility"}
+
 type pokemonEntry struct {
 	createdAt time.Time
 	data      []byte
+	// parsed caches data once unmarshalled, see GetPokemon.
+	parsed *Pokemon
 }
 
 type pokedex struct {
 	entries map[string]pokemonEntry
 	mu      sync.Mutex
+	clock   pokecache.Clock
+	// count mirrors len(entries) so it can be read without the lock.
+	count atomic.Int64
 }
 
 func (p *pokedex) Add(key string, data []byte) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	if _, exists := p.entries[key]; !exists {
+		p.count.Add(1)
+	}
 	p.entries[key] = pokemonEntry{
-		createdAt: time.Now(),
+		createdAt: p.clock.Now(),
 		data:      data,
 	}
 	return nil
@@ -53,9 +71,67 @@ func (p *pokedex) Get(key string) ([]byte, error) {
 	return entry.data, nil
 }
 
+// Count returns the number of caught pokemon without taking the lock.
+func (p *pokedex) Count() int {
+	return int(p.count.Load())
+}
+
+// GetPokemon returns the parsed pokemon stored under key. The JSON is only
+// unmarshalled on first access; replacing the entry with Add drops the
+// parsed copy.
+func (p *pokedex) GetPokemon(key string) (Pokemon, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.entries[key]
+	if !ok {
+		return Pokemon{}, errors.New("key not found")
+	}
+	if entry.parsed == nil {
+		var pokemon Pokemon
+		if err := json.Unmarshal(entry.data, &pokemon); err != nil {
+			return Pokemon{}, err
+		}
+		entry.parsed = &pokemon
+		p.entries[key] = entry
+	}
+	return *entry.parsed, nil
+}
+
+// Names returns the keys of every entry, sorted. It is safe to call while
+// other goroutines add entries.
+func (p *pokedex) Names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	names := make([]string, 0, len(p.entries))
+	for name := range p.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Recent returns the keys of the n most recently added entries, newest
+// first.
+func (p *pokedex) Recent(n int) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]string, 0, len(p.entries))
+	for key := range p.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return p.entries[keys[i]].createdAt.After(p.entries[keys[j]].createdAt)
+	})
+	if n < len(keys) {
+		keys = keys[:n]
+	}
+	return keys
+}
+
 func NewPokedex() *pokedex {
 	p := &pokedex{
 		entries: make(map[string]pokemonEntry),
+		clock:   pokecache.RealClock,
 	}
 
 	return p
@@ -65,25 +141,40 @@ var pCache *pokecache.Cache
 var pDex *pokedex
 var commands map[string]cliCommand
 
+// cacheDir is where the cache is persisted between sessions, see
+// resolveCacheDir. The disk cache is disabled when it is empty.
+var cacheDir string
+
+// flushTimeout bounds how long exiting may spend writing the cache to disk.
+const flushTimeout = 2 * time.Second
+
+// offline disables network access. POKEDEX_OFFLINE=1 turns it on at startup.
+var offline bool
+
+// rng drives every random roll. Setting POKEDEX_SEED makes it deterministic.
+var rng *rand.Rand
+
 func init() {
-	api := &PokeAPI{}
-	nextURL := "https://pokeapi.co/api/v2/location-area"
-	api.NextURL = &nextURL
-	pCache = pokecache.NewCache()
-	pDex = NewPokedex()
+	api := newPaginator(locationAreaURL)
+	api.Scrollback = pScrollback
+	items := newPaginator(primaryBaseURL + "/item")
+	berries := newPaginator(primaryBaseURL + "/berry")
 	commands = make(map[string]cliCommand)
 	commands["help"] = cliCommand{
 		name:        "help",
-		description: "Displays a help message",
+		category:    "utility",
+		description: "Displays a help message. Use --offline to only list offline-capable commands",
 		callback:    commandHelp,
 	}
 	commands["exit"] = cliCommand{
 		name:        "exit",
+		category:    "utility",
 		description: "Exits the Pokedex",
 		callback:    commandExit,
 	}
 	commands["map"] = cliCommand{
 		name:        "map",
+		category:    "exploration",
 		description: "Get the next 20 results from the location API",
 		callback: func(params ...string) error {
 			return api.commandMap("next")
@@ -91,34 +182,372 @@ func init() {
 	}
 	commands["mapb"] = cliCommand{
 		name:        "mapb",
+		category:    "exploration",
 		description: "Get the previous 20 results from the location API",
 		callback: func(params ...string) error {
 			return api.commandMap("prev")
 		},
 	}
+	commands["items"] = cliCommand{
+		name:        "items",
+		category:    "exploration",
+		description: "Get the next 20 items, or the previous 20 with 'items prev'",
+		callback: func(params ...string) error {
+			return items.commandMap(pageDirection(params))
+		},
+	}
+	commands["berries"] = cliCommand{
+		name:        "berries",
+		category:    "exploration",
+		description: "Get the next 20 berries, or the previous 20 with 'berries prev'",
+		callback: func(params ...string) error {
+			return berries.commandMap(pageDirection(params))
+		},
+	}
+	commands["mapjump"] = cliCommand{
+		name:        "mapjump",
+		category:    "exploration",
+		description: "Jump to <page> of the location API; map and mapb continue from there",
+		callback:    api.commandMapJump,
+	}
+	commands["recent"] = cliCommand{
+		name:        "recent",
+		category:    "exploration",
+		description: "Shows the last location pages listed by map and mapb. Accepts --limit <n>",
+		callback:    commandRecent,
+	}
+	commands["scrollback"] = cliCommand{
+		name:        "scrollback",
+		category:    "utility",
+		description: "Shows or sets <n>, the number of location pages recent keeps.",
+		callback:    commandScrollback,
+	}
+	commands["mapall"] = cliCommand{
+		name:        "mapall",
+		category:    "exploration",
+		description: "List every location from the location API, or write them all to [file]. Accepts --limit <n>",
+		callback:    commandMapAll,
+	}
+	commands["whatsnew"] = cliCommand{
+		name:        "whatsnew",
+		category:    "exploration",
+		description: "Lists locations added or removed since the last snapshot in [file] (default ~/.pokedex_locations), then updates it",
+		callback:    commandWhatsNew,
+	}
+	commands["mapfind"] = cliCommand{
+		name:        "mapfind",
+		category:    "exploration",
+		description: "Search every location for names containing <text>, with the page each is on. Accepts --limit <n>",
+		callback:    commandMapFind,
+	}
 	commands["explore"] = cliCommand{
 		name:        "explore",
-		description: "Explore <location> to find Pokemon, with <location> being the name or id of the location",
+		category:    "exploration",
+		description: "Explore <location> to find Pokemon, with <location> being the name or id of the location. Add --urls to show each pokemon's URL. Accepts --limit <n>",
 		callback:    commandExplore,
 	}
+	commands["comparelocs"] = cliCommand{
+		name:        "comparelocs",
+		category:    "exploration",
+		description: "Shows which pokemon are unique to <location1> or <location2>, and which they share",
+		callback:    commandCompareLocs,
+	}
+	commands["search"] = cliCommand{
+		name:        "search",
+		category:    "exploration",
+		description: "Lists pokemon whose name starts with <prefix>. Accepts --limit <n>.",
+		callback:    commandSearch,
+	}
+	commands["types"] = cliCommand{
+		name:        "types",
+		category:    "exploration",
+		description: "Lists every pokemon type. Accepts --limit <n>.",
+		callback:    commandTypes,
+	}
+	commands["typechart"] = cliCommand{
+		name:        "typechart",
+		category:    "exploration",
+		description: "Shows what <type> is strong and weak against, attacking and defending.",
+		callback:    commandTypeChart,
+	}
 	commands["catch"] = cliCommand{
 		name:        "catch",
-		description: "Try to catch <pokemon>, with <pokemon> being the name or id of the pokemon you are trying to catch.",
+		category:    "collection",
+		description: "Try to catch <pokemon>, with <pokemon> being the name or id of the pokemon you are trying to catch. Add --ball great|ultra|master to improve the odds, --retry404 to retry pokemon pokeapi does not know yet, or --dry-run to see the outcome without catching.",
 		callback:    commandCatch,
 	}
 
+	commands["rewind"] = cliCommand{
+		name:        "rewind",
+		category:    "collection",
+		description: "Removes the pokemon you caught last from your pokedex. Only the last catch can be undone.",
+		callback:    commandRewind,
+	}
+
+	commands["catchtype"] = cliCommand{
+		name:        "catchtype",
+		category:    "collection",
+		description: "Explores <location> and tries to catch the first pokemon found there of <type>. Accepts --ball like catch.",
+		callback:    commandCatchType,
+	}
+
+	commands["catchrate"] = cliCommand{
+		name:        "catchrate",
+		category:    "collection",
+		description: "Shows the chance of catching <pokemon> without throwing a ball.",
+		callback:    commandCatchRate,
+	}
+
+	commands["catchlog"] = cliCommand{
+		name:        "catchlog",
+		category:    "collection",
+		description: "Lists your recent catch attempts. Accepts --limit <n>.",
+		callback:    commandCatchLog,
+	}
+
+	commands["seen"] = cliCommand{
+		name:        "seen",
+		category:    "collection",
+		description: "Lists pokemon you have looked up but not caught yet. Accepts --limit <n>.",
+		callback:    commandSeen,
+	}
+	commands["coverage"] = cliCommand{
+		name:        "coverage",
+		category:    "collection",
+		description: "Shows which types your caught pokemon, or those of the profile [file], cover and which are missing.",
+		callback:    commandCoverage,
+	}
+	commands["compareteams"] = cliCommand{
+		name:        "compareteams",
+		category:    "collection",
+		description: "Compares the pokemon of two saved profiles <fileA> <fileB>: total stats, type coverage and shared pokemon.",
+		callback:    commandCompareTeams,
+	}
+	commands["randomteam"] = cliCommand{
+		name:        "randomteam",
+		category:    "collection",
+		description: "Suggests a team of six random pokemon. Set POKEDEX_SEED for a repeatable team",
+		callback:    commandRandomTeam,
+	}
 	commands["inspect"] = cliCommand{
 		name:        "inspect",
-		description: "Inspect the following <pokemon>, with <pokemon> being the name or id of the pokemon you are trying to inspect. You can only inspect a pokemon you have caught.",
+		category:    "collection",
+		description: "Inspect the following <pokemon>, with <pokemon> being the name or id of the pokemon you are trying to inspect. You can only inspect a pokemon you have caught. Add a [form] (e.g. 'inspect deoxys attack') to show a specific form, --bars to chart the stats, --long to show full stat names, --gen to show its generation or --base to compare its stats with its base form.",
 		callback:    commandInspect,
 	}
 
+	commands["moves"] = cliCommand{
+		name:        "moves",
+		category:    "collection",
+		description: "Lists the moves a caught <pokemon> can learn. Accepts --limit <n>.",
+		callback:    commandMoves,
+	}
+
+	commands["bestmove"] = cliCommand{
+		name:        "bestmove",
+		category:    "collection",
+		description: "Finds the most powerful damaging move of a caught <pokemon>.",
+		callback:    commandBestMove,
+	}
+
+	commands["movecount"] = cliCommand{
+		name:        "movecount",
+		category:    "collection",
+		description: "Ranks caught pokemon by how many distinct moves they can learn. Accepts --limit <n>.",
+		callback:    commandMoveCount,
+	}
+
+	commands["movelimit"] = cliCommand{
+		name:        "movelimit",
+		category:    "utility",
+		description: "Shows or sets <n>, the maximum number of moves listed. It takes precedence over maxresults for moves.",
+		callback:    commandMoveLimit,
+	}
+
+	commands["maxresults"] = cliCommand{
+		name:        "maxresults",
+		category:    "utility",
+		description: "Shows or sets <n>, the maximum number of results list commands print (moves follows movelimit). Override it once with --limit <n>.",
+		callback:    commandMaxResults,
+	}
+
+	commands["cry"] = cliCommand{
+		name:        "cry",
+		category:    "collection",
+		description: "Shows where to listen to a caught <pokemon>'s cry.",
+		callback:    commandCry,
+	}
+
+	commands["sprite"] = cliCommand{
+		name:        "sprite",
+		category:    "collection",
+		description: "Shows the sprite URL of a caught <pokemon>, and downloads it to [file] if given.",
+		callback:    commandSprite,
+	}
+
+	commands["trainer"] = cliCommand{
+		name:        "trainer",
+		category:    "collection",
+		description: "Shows your trainer XP and level.",
+		callback:    commandTrainer,
+	}
+
+	commands["luckyegg"] = cliCommand{
+		name:        "luckyegg",
+		category:    "collection",
+		description: "Toggles a lucky egg, doubling catch XP for 5 minutes.",
+		callback:    commandLuckyEgg,
+	}
+
+	commands["refresh"] = cliCommand{
+		name:         "refresh",
+		category:     "collection",
+		needsNetwork: true,
+		description:  "Fetches fresh data for a caught <pokemon> and shows what changed.",
+		callback:     commandRefresh,
+	}
+
 	commands["pokedex"] = cliCommand{
 		name:        "pokedex",
-		description: "Displays a list of all pokemons you have caught.",
+		category:    "collection",
+		description: "Displays a list of all pokemons you have caught. Use 'pokedex legendaries' to list only legendary ones, or 'pokedex recent [n]' for the last n caught. Accepts --limit <n>.",
 		callback:    commandPokedex,
 	}
 
+	commands["dicesides"] = cliCommand{
+		name:        "dicesides",
+		category:    "utility",
+		description: "Shows or sets <n>, the number of sides of the catch die. More sides make catching harder.",
+		callback:    commandDiceSides,
+	}
+
+	commands["catchsim"] = cliCommand{
+		name:        "catchsim",
+		category:    "utility",
+		description: "Rolls the catch die <n> times against <pokemon> without catching it and prints the success rate. Accepts --ball like catch.",
+		callback:    commandCatchSim,
+	}
+
+	commands["weather"] = cliCommand{
+		name:        "weather",
+		category:    "utility",
+		description: "Shows the weather of this session and the types it makes easier to catch.",
+		callback:    commandWeather,
+	}
+
+	commands["catchdelay"] = cliCommand{
+		name:        "catchdelay",
+		category:    "utility",
+		description: "Shows or sets the pause of the catch animation, e.g. 'catchdelay 200ms' or 'catchdelay off'.",
+		callback:    commandCatchDelay,
+	}
+
+	commands["cooldown"] = cliCommand{
+		name:        "cooldown",
+		category:    "utility",
+		description: "Shows or sets <seconds> to wait before throwing at the same pokemon again. 0 disables it.",
+		callback:    commandCooldown,
+	}
+
+	commands["reset"] = cliCommand{
+		name:        "reset",
+		category:    "utility",
+		description: "Clears session counters, cache stats, the catch log and trainer XP. Your pokedex is kept.",
+		callback:    commandReset,
+	}
+
+	commands["ping"] = cliCommand{
+		name:         "ping",
+		category:     "utility",
+		needsNetwork: true,
+		description:  "Checks that pokeapi and any mirrors are reachable and shows the round-trip times.",
+		callback:     commandPing,
+	}
+
+	commands["offline"] = cliCommand{
+		name:        "offline",
+		category:    "utility",
+		description: "Shows or sets offline mode with 'offline on|off'. Use 'help --offline' to list the commands that still work.",
+		callback:    commandOffline,
+	}
+
+	commands["dedupe"] = cliCommand{
+		name:        "dedupe",
+		category:    "utility",
+		description: "Merges pokedex entries holding the same pokemon, keeping the newest.",
+		callback:    commandDedupe,
+	}
+
+	commands["cacheexport"] = cliCommand{
+		name:        "cacheexport",
+		category:    "utility",
+		description: "Save the cache to <file> as gzipped JSON so it can be shared.",
+		callback:    commandCacheExport,
+	}
+
+	commands["cacheimport"] = cliCommand{
+		name:        "cacheimport",
+		category:    "utility",
+		description: "Load cache entries from <file>, skipping expired ones.",
+		callback:    commandCacheImport,
+	}
+
+	commands["suggest"] = cliCommand{
+		name:        "suggest",
+		category:    "exploration",
+		description: "Suggests a pokemon you have not caught yet.",
+		callback:    commandSuggest,
+	}
+
+	commands["serve"] = cliCommand{
+		name:        "serve",
+		category:    "utility",
+		description: "Starts an HTTP server on [addr] (default localhost:8080) with GET /metrics for monitoring.",
+		callback:    commandServe,
+	}
+
+	commands["cachestats"] = cliCommand{
+		name:        "cachestats",
+		category:    "utility",
+		description: "Displays cache hits, misses and the hit ratio.",
+		callback:    commandCacheStats,
+	}
+
+	commands["validatecache"] = cliCommand{
+		name:        "validatecache",
+		category:    "utility",
+		description: "Checks the disk cache for corrupt files. Add --remove to delete them.",
+		callback:    commandValidateCache,
+	}
+
+	commands["cachedump"] = cliCommand{
+		name:        "cachedump",
+		category:    "utility",
+		description: "Lists every cache entry with its size and age, largest first. Accepts --limit <n>.",
+		callback:    commandCacheDump,
+	}
+
+	commands["prewarm"] = cliCommand{
+		name:        "prewarm",
+		category:    "utility",
+		description: "Fetches and caches every pokeapi URL listed in <file>, one per line, skipping those already cached. Useful before going offline.",
+		callback:    commandPrewarm,
+	}
+
+	commands["replay"] = cliCommand{
+		name:        "replay",
+		category:    "utility",
+		description: "Runs the commands listed in <file>, one per line. Stops at the first error unless --continue is given.",
+		callback:    commandReplay,
+	}
+}
+
+func newRNG(seed string) *rand.Rand {
+	n, err := strconv.ParseInt(seed, 10, 64)
+	if err != nil {
+		n = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(n))
 }
 
 type PokeLoc struct {
@@ -131,6 +560,16 @@ type PokeLoc struct {
 	} `json:"results"`
 }
 
+type PokeList struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"results"`
+}
+
 type PokeLocal struct {
 	ID                int    `json:"id"`
 	Name              string `json:"name"`
@@ -162,6 +601,12 @@ type Pokemon struct {
 		Name string `json:"name"`
 		URL  string `json:"url"`
 	} `json:"forms"`
+	HeldItems []struct {
+		Item struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"item"`
+	} `json:"held_items"`
 	LocationAreaEncounters string `json:"location_area_encounters"`
 	Moves                  []struct {
 		Move struct {
@@ -188,6 +633,10 @@ type Pokemon struct {
 		Latest string `json:"latest"`
 		Legacy string `json:"legacy"`
 	} `json:"cries"`
+	Sprites struct {
+		FrontDefault *string `json:"front_default"`
+		FrontShiny   *string `json:"front_shiny"`
+	} `json:"sprites"`
 	Stats []struct {
 		BaseStat int `json:"base_stat"`
 		Effort   int `json:"effort"`
@@ -205,64 +654,133 @@ type Pokemon struct {
 	} `json:"types"`
 }
 
+type PokemonForm struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	FormName string `json:"form_name"`
+	IsMega   bool   `json:"is_mega"`
+	Pokemon  struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"pokemon"`
+	Types []struct {
+		Slot int `json:"slot"`
+		Type struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"type"`
+	} `json:"types"`
+}
+
 type PokeAPI struct {
+	BaseURL string
 	NextURL *string
 	PrevURL *string
+	// Count is the total number of results, known once a page was fetched.
+	Count int
+	// Scrollback, when set, keeps the pages shown by commandMap.
+	Scrollback *scrollback
 }
 
 func commandExit(params ...string) error {
-	os.Exit(0)
+	endSession(0)
 	return nil
 }
 
-func commandExplore(params ...string) error {
-	if len(params) < 1 {
-		fmt.Println("Please provide a location name")
-		return errors.New("no location name provided")
-	}
+// endSession is how the REPL ends, whether through exit or the end of its
+// input: it prints the session summary, saves the cache and exits with code.
+func endSession(code int) {
+	printSessionSummary()
+	flushCache()
+	os.Exit(code)
+}
 
-	fmt.Println("Exploring location:", params[0])
-	var resp *http.Response
-	var err error
-	url := "https://pokeapi.co/api/v2/location-area/" + params[0]
+func printSessionSummary() {
+	hits, misses := pCache.Stats()
+	fmt.Print(currentSession.Summary(hits, misses, time.Now()))
+}
 
-	// Check the cache
-	data, err := pCache.Get(url)
-	if err == nil {
-		// Cache hit
-		return processExplore(data)
+// flushCache saves the cache to cacheDir, giving up after flushTimeout.
+func flushCache() {
+	if cacheDir == "" {
+		return
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	n, err := pCache.Flush(ctx, cacheDir)
+	cancel()
+	if err != nil {
+		fmt.Printf("Error saving cache (%d entries saved): %v\n", n, err)
+	}
+}
 
-	resp, err = http.Get(url)
+func commandValidateCache(params ...string) error {
+	_, flags := parseFlags(params)
+	if cacheDir == "" {
+		fmt.Println("The disk cache is disabled")
+		return nil
+	}
+	valid, corrupt, err := pokecache.Validate(cacheDir, flags["remove"])
 	if err != nil {
-		fmt.Println("Error fetching data:", err)
+		fmt.Println("Error validating cache:", err)
 		return err
 	}
+	fmt.Printf("%d valid cache files in %s\n", valid, cacheDir)
+	if len(corrupt) == 0 {
+		return nil
+	}
+	fmt.Printf("%d corrupt cache files:\n", len(corrupt))
+	for _, name := range corrupt {
+		fmt.Println("  -", name)
+	}
+	if flags["remove"] {
+		fmt.Println("Corrupt files removed.")
+	} else {
+		fmt.Println("Run 'validatecache --remove' to delete them.")
+	}
+	return nil
+}
 
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+// exitCode maps the error of a command to the process exit status used
+// outside interactive mode: 0 on success, 2 for an unknown command and 1
+// for any other failure.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUnknownCommand):
+		return 2
+	default:
+		return 1
+	}
+}
+
+func commandExplore(params ...string) error {
+	limit, params, err := takeLimit(params, maxResults)
 	if err != nil {
-		fmt.Println("Error reading response:", err)
 		return err
 	}
-	// Check if the content type is JSON
-	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
-		fmt.Println("Response is not JSON:", contentType)
-		return errors.New("response is not JSON")
+	params, flags := parseFlags(params)
+	if len(params) < 1 {
+		fmt.Println("Please provide a location name")
+		return errors.New("no location name provided")
 	}
-	// Cache the response body
-	err = pCache.Add(url, body)
+
+	fmt.Println("Exploring location:", params[0])
+	url := locationAreaURL + "/" + params[0]
+
+	body, err := fetchValid(url, validateLocationArea)
 	if err != nil {
-		fmt.Println("Error adding to cache:", err)
 		return err
 	}
 
 	// Process the response body
-	return processExplore(body)
+	return processExplore(body, flags["urls"], limit)
 
 }
 
-func processExplore(data []byte) error {
+// processExplore prints up to limit of the pokemon found in a location-area,
+// along with their pokeapi URLs when withURLs is set.
+func processExplore(data []byte, withURLs bool, limit int) error {
 	var locs PokeLocal
 
 	err := json.Unmarshal(data, &locs)
@@ -272,19 +790,25 @@ func processExplore(data []byte) error {
 	}
 
 	// Print the struct to verify
-	if len(locs.PokemonEncounters) > 0 {
-		fmt.Println("Pokemon found:")
+	if len(locs.PokemonEncounters) == 0 {
+		fmt.Println("No pokemon encounters at this location.")
+		return nil
 	}
+	fmt.Println("Pokemon found:")
+	lines := make([]string, 0, len(locs.PokemonEncounters))
 	for _, loc := range locs.PokemonEncounters {
-		fmt.Println(loc.Pokemon.Name)
+		if withURLs {
+			lines = append(lines, fmt.Sprintf("%-20s %s", truncateRunes(loc.Pokemon.Name, 20), loc.Pokemon.URL))
+			continue
+		}
+		lines = append(lines, loc.Pokemon.Name)
 	}
+	printList(lines, limit)
 
 	return nil
 }
 
 func (api *PokeAPI) commandMap(dir string) error {
-	var resp *http.Response
-	var err error
 	var url string
 	if dir == "next" {
 		if api.NextURL == nil {
@@ -302,42 +826,16 @@ func (api *PokeAPI) commandMap(dir string) error {
 		fmt.Println("Invalid direction")
 		return errors.New("invalid direction")
 	}
-	// Check the cache
-	data, err := pCache.Get(url)
-	if err == nil {
-		// Cache hit
-		return processResponse(data, api)
-	}
-
-	resp, err = http.Get(url)
-	if err != nil {
-		fmt.Println("Error fetching data:", err)
-		return err
-	}
-
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+	body, err := fetchValid(url, validateList)
 	if err != nil {
-		fmt.Println("Error reading response:", err)
-		return err
-	}
-	// Check if the content type is JSON
-	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
-		fmt.Println("Response is not JSON:", contentType)
-		return errors.New("response is not JSON")
-	}
-	// Cache the response body
-	err = pCache.Add(url, body)
-	if err != nil {
-		fmt.Println("Error adding to cache:", err)
 		return err
 	}
 
 	// Process the response body
-	return processResponse(body, api)
+	return processResponse(body, api, url)
 }
 
-func processResponse(data []byte, api *PokeAPI) error {
+func processResponse(data []byte, api *PokeAPI, url string) error {
 	var locs PokeLoc
 
 	err := json.Unmarshal(data, &locs)
@@ -347,115 +845,360 @@ func processResponse(data []byte, api *PokeAPI) error {
 	}
 
 	// Print the struct to verify
+	names := make([]string, 0, len(locs.Results))
 	for _, loc := range locs.Results {
 		fmt.Println(loc.Name)
+		names = append(names, loc.Name)
+	}
+	if api.Scrollback != nil {
+		api.Scrollback.Record(pageNumber(url), names)
 	}
 
-	api.NextURL = locs.Next
-	api.PrevURL = locs.Previous
+	api.NextURL = resolveCursor(url, locs.Next)
+	api.PrevURL = resolveCursor(url, locs.Previous)
+	api.Count = locs.Count
 
 	return nil
 }
 
 func commandHelp(params ...string) error {
+	_, flags := parseFlags(params)
 	keys := make([]string, 0, len(commands))
 	for k := range commands {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
-	fmt.Println("Welcome to the Pokedex!")
+	fmt.Printf("Welcome to the Pokedex, %s!\n", pTrainer.DisplayName())
 	fmt.Println()
 	fmt.Println("Available commands:")
-	fmt.Println()
-	for _, k := range keys {
-		fmt.Printf("%s: %s\n", k, commands[k].description)
+	for _, category := range categories {
+		lines := make([]string, 0)
+		for _, k := range keys {
+			cmd := commands[k]
+			// Aliases share their command's name; only list the command once.
+			if cmd.category != category || cmd.name != k {
+				continue
+			}
+			if flags["offline"] && cmd.needsNetwork {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s", k, cmd.description))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Println()
+		fmt.Printf("%s:\n", strings.ToUpper(category[:1])+category[1:])
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	}
+	return nil
+}
+
+func commandOffline(params ...string) error {
+	if len(params) > 0 {
+		switch params[0] {
+		case "on":
+			offline = true
+		case "off":
+			offline = false
+		default:
+			fmt.Println("Please use 'offline on' or 'offline off'")
+			return errors.New("invalid offline mode")
+		}
+	}
+	if offline {
+		fmt.Println("Offline mode is on")
+	} else {
+		fmt.Println("Offline mode is off")
 	}
 	return nil
 }
 
 func commandPokedex(params ...string) error {
-	fmt.Println("Pokedex:")
-	for name := range pDex.entries {
+	limit, params, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	if len(params) > 0 && params[0] == "legendaries" {
+		return listLegendaries(limit)
+	}
+	if len(params) > 0 && params[0] == "recent" {
+		return listRecent(params[1:]...)
+	}
+	names := pDex.Names()
+	if len(names) == 0 {
+		fmt.Println("You haven't caught any pokemon yet. Try 'explore' then 'catch'!")
+		return nil
+	}
+	fmt.Printf("Pokedex (%d caught):\n", len(names))
+	printList(names, limit)
+	return nil
+}
+
+func listRecent(params ...string) error {
+	n := 5
+	if len(params) > 0 {
+		var err error
+		n, err = strconv.Atoi(params[0])
+		if err != nil || n < 1 {
+			fmt.Println("Please provide a positive number")
+			return errors.New("invalid count")
+		}
+	}
+	fmt.Println("Recently caught:")
+	for _, name := range pDex.Recent(n) {
 		fmt.Println("  -", name)
 	}
 	return nil
 }
 
+func commandCacheExport(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a file name")
+		return errors.New("no file name provided")
+	}
+	file, err := os.Create(params[0])
+	if err != nil {
+		fmt.Println("Error creating file:", err)
+		return err
+	}
+	defer file.Close()
+
+	err = pCache.Export(file)
+	if err != nil {
+		fmt.Println("Error exporting cache:", err)
+		return err
+	}
+	fmt.Println("Cache exported to", params[0])
+	return nil
+}
+
+func commandCacheImport(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a file name")
+		return errors.New("no file name provided")
+	}
+	file, err := os.Open(params[0])
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return err
+	}
+	defer file.Close()
+
+	n, err := pCache.Import(file)
+	if err != nil {
+		fmt.Println("Error importing cache:", err)
+		return err
+	}
+	fmt.Printf("Imported %d cache entries from %s\n", n, params[0])
+	return nil
+}
+
+func commandCacheStats(params ...string) error {
+	hits, misses := pCache.Stats()
+	fmt.Printf("Hits: %d\n", hits)
+	fmt.Printf("Misses: %d\n", misses)
+	fmt.Printf("Hit ratio: %.1f%%\n", hitRatio(hits, misses)*100)
+	return nil
+}
+
+func commandCacheDump(params ...string) error {
+	limit, _, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	entries := pCache.Entries()
+	now := pDex.clock.Now()
+	total := 0
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		age := "pinned"
+		if !entry.Pinned {
+			age = now.Sub(entry.CreatedAt).Round(time.Second).String()
+		}
+		lines = append(lines, fmt.Sprintf("%8d B  %-8s %s", entry.Size, truncateRunes(age, 8), entry.Key))
+		total += entry.Size
+	}
+	printRows(lines, limit)
+	fmt.Printf("Total: %d bytes in %d entries\n", total, len(entries))
+	return nil
+}
+
+func hitRatio(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
+
+func commandSuggest(params ...string) error {
+	body, err := fetchValid(primaryBaseURL+"/pokemon", validateList)
+	if err != nil {
+		return err
+	}
+	var list PokeList
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+		return err
+	}
+	name, ok := suggestPokemon(list, pDex)
+	if !ok {
+		fmt.Println("You have caught every pokemon on the first page, impressive!")
+		return nil
+	}
+	fmt.Printf("Why not try to catch %s? (catch %s)\n", name, name)
+	return nil
+}
+
+// suggestPokemon picks a random name from list that is not in dex.
+func suggestPokemon(list PokeList, dex *pokedex) (string, bool) {
+	uncaught := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		if _, err := dex.Get(result.Name); err != nil {
+			uncaught = append(uncaught, result.Name)
+		}
+	}
+	if len(uncaught) == 0 {
+		return "", false
+	}
+	return uncaught[rng.Intn(len(uncaught))], true
+}
+
 func commandInspect(params ...string) error {
+	params, flags := parseFlags(params)
 	if len(params) < 1 {
 		fmt.Println("Please provide a Pokemon name")
 		return errors.New("no Pokemon name provided")
 	}
-	data, err := pDex.Get(params[0])
+	pokemon, err := pDex.GetPokemon(params[0])
+	if err != nil && len(matchSubstring(pDex.Names(), strings.ToLower(params[0]))) > 0 {
+		// Only part of a caught pokemon's name was typed.
+		name, resolveErr := disambiguate(params[0], pDex.Names())
+		if resolveErr != nil {
+			return resolveErr
+		}
+		pokemon, err = pDex.GetPokemon(name)
+	}
 	if err != nil {
 		fmt.Println("You have not caught that pokemon yet (or there was an error):", params[0])
 		return err
 	}
-	var pokemon Pokemon
-	err = json.Unmarshal(data, &pokemon)
-	if err != nil {
-		fmt.Println("Error unmarshalling JSON:", err)
-		return err
+	if len(params) > 1 {
+		err = applyForm(&pokemon, params[1])
+		if err != nil {
+			return err
+		}
 	}
 	fmt.Printf("Name: %s\n", pokemon.Name)
 	fmt.Printf("Height: %d\n", pokemon.Height)
 	fmt.Printf("Weight: %d\n", pokemon.Weight)
+	if flags["gen"] {
+		fmt.Printf("Generation: %s\n", generation(pokemon))
+	}
 	fmt.Println("Stats:")
 	for _, stat := range pokemon.Stats {
-		fmt.Printf("  -%s: %d\n", stat.Stat.Name, stat.BaseStat)
+		value := colorize(strconv.Itoa(stat.BaseStat), statColor(stat.BaseStat))
+		name := abbreviateStat(stat.Stat.Name)
+		if flags["long"] {
+			name = stat.Stat.Name
+		}
+		if flags["bars"] {
+			fmt.Printf("  -%-16s %s %s\n", name, renderBar(stat.BaseStat), value)
+			continue
+		}
+		fmt.Printf("  -%s: %s\n", name, value)
 	}
 	fmt.Println("Types:")
 	for _, t := range pokemon.Types {
 		fmt.Println("  - ", t.Type.Name)
 	}
+	fmt.Println("Held items:", heldItems(pokemon))
+	if flags["base"] {
+		return printBaseDeltas(pokemon)
+	}
 	return nil
 }
 
-func commandCatch(params ...string) error {
-	if len(params) < 1 {
-		fmt.Println("Please provide a Pokemon name")
-		return errors.New("no Pokemon name provided")
+// heldItems lists the items pokemon may hold in the wild, or "none".
+func heldItems(pokemon Pokemon) string {
+	if len(pokemon.HeldItems) == 0 {
+		return "none"
 	}
-	var resp *http.Response
-	var err error
-	url := "https://pokeapi.co/api/v2/pokemon/" + params[0]
-
-	// Check the cache
-	data, err := pCache.Get(url)
-	if err == nil {
-		// Cache hit
-		return processCatch(data)
+	names := make([]string, 0, len(pokemon.HeldItems))
+	for _, held := range pokemon.HeldItems {
+		names = append(names, held.Item.Name)
 	}
+	return strings.Join(names, ", ")
+}
 
-	resp, err = http.Get(url)
+// applyForm fetches the pokemon-form named "<pokemon>-<form>" and merges its
+// form-specific data into pokemon.
+func applyForm(pokemon *Pokemon, form string) error {
+	url := primaryBaseURL + "/pokemon-form/" + pokemon.Name + "-" + form
+	body, err := fetch(url)
 	if err != nil {
-		fmt.Println("Error fetching data:", err)
 		return err
 	}
+	var pokeForm PokemonForm
+	err = json.Unmarshal(body, &pokeForm)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+		return err
+	}
+	fmt.Printf("Form: %s\n", pokeForm.FormName)
+	if len(pokeForm.Types) > 0 {
+		pokemon.Types = pokeForm.Types
+	}
+	return nil
+}
 
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
+func commandCatch(params ...string) error {
+	ballName, params := takeFlagValue(params, "ball")
+	ball, err := lookupBall(ballName)
 	if err != nil {
-		fmt.Println("Error reading response:", err)
+		fmt.Println(err)
 		return err
 	}
-	// Check if the content type is JSON
-	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "application/json") {
-		fmt.Println("Response is not JSON:", contentType)
-		return errors.New("response is not JSON")
+	params, flags := parseFlags(params)
+	if len(params) < 1 {
+		fmt.Println("Please provide a Pokemon name")
+		return errors.New("no Pokemon name provided")
+	}
+	url := pokemonPrefix + params[0]
+
+	var body []byte
+	if flags["retry404"] {
+		body, err = fetchPokemonRetrying(url)
+	} else {
+		body, err = fetchValid(url, validatePokemon)
+	}
+	if isNotFound(err) {
+		// Maybe only part of the name was typed.
+		names, namesErr := pokemonNames()
+		if namesErr != nil {
+			return err
+		}
+		name, resolveErr := disambiguate(params[0], names)
+		if resolveErr != nil {
+			return resolveErr
+		}
+		body, err = fetchValid(pokemonPrefix+name, validatePokemon)
 	}
-	// Cache the response body
-	err = pCache.Add(url, body)
 	if err != nil {
-		fmt.Println("Error adding to cache:", err)
 		return err
 	}
 
 	// Process the response body
-	return processCatch(body)
+	return processCatch(body, ball, flags["dry-run"])
 }
 
-func processCatch(data []byte) error {
+// processCatch throws ball at the pokemon in data, unless it is still
+// cooling down from the last throw. With dryRun the outcome is only
+// reported: nothing is stored, logged or awarded, and no cooldown starts.
+func processCatch(data []byte, ball pokeball, dryRun bool) error {
 	var pokemon Pokemon
 
 	err := json.Unmarshal(data, &pokemon)
@@ -463,85 +1206,197 @@ func processCatch(data []byte) error {
 		fmt.Println("Error unmarshalling JSON:", err)
 		return err
 	}
+	if remaining := pCooldown.Remaining(pokemon.Name); remaining > 0 {
+		fmt.Printf("%s is still cooling down, wait %s\n", pokemon.Name, remaining.Round(time.Second))
+		return nil
+	}
+	if !dryRun {
+		pCooldown.Mark(pokemon.Name)
+	}
 
 	// Print the struct to verify
-	fmt.Printf("Throwing a Pokeball at %s...\n", pokemon.Name)
-	dice := rand.Intn(10)
-	if dice*pokemon.BaseExperience > 400 {
+	fmt.Printf("Throwing a %s at %s...\n", ball.name, pokemon.Name)
+	catchAnimation()
+	if currentWeather.Boosts(pokemon) {
+		fmt.Printf("The %s weather favors %s!\n", currentWeather.name, pokemon.Name)
+		ball = currentWeather.apply(ball, pokemon)
+	}
+	experience := effectiveExperience(pokemon.BaseExperience, ball)
+	dice := rng.Intn(diceSides)
+	caught := catchSucceeds(dice, experience)
+	if dryRun {
+		if caught {
+			fmt.Println("Dry run: you would have caught", pokemon.Name)
+		} else {
+			fmt.Println("Dry run:", pokemon.Name, "would have escaped")
+			fmt.Printf("Dice Roll : %d * %d > %d\n", dice, experience, catchThreshold)
+		}
+		return nil
+	}
+	pCatchLog.Record(catchAttempt{
+		name:   pokemon.Name,
+		caught: caught,
+		at:     pDex.clock.Now(),
+		dice:   dice,
+		chance: CalculateCatchChance(pokemon.BaseExperience, ball),
+	})
+	if !caught {
 		fmt.Println("Oh no! The", pokemon.Name, "escaped!")
-		fmt.Printf("Dice Roll : %d * %d > 400\n", dice, pokemon.BaseExperience)
+		fmt.Printf("Dice Roll : %d * %d > %d\n", dice, experience, catchThreshold)
+		if err := pTrainer.RecordEscape(); err != nil {
+			fmt.Println("Error saving trainer:", err)
+		}
 	} else {
 		fmt.Println("Gotcha! You caught a", pokemon.Name)
-		pDex.Add(pokemon.Name, data)
+		recordCatch(pDex, pokemon.Name, data)
+		if err := saveProfile(); err != nil {
+			fmt.Println("Error saving pokedex:", err)
+		}
+		currentSession.CountCatch()
+		xp, bonus, err := pTrainer.RecordCatch(pokemon.BaseExperience)
+		if err != nil {
+			fmt.Println("Error saving trainer:", err)
+		}
+		if bonus > 0 {
+			fmt.Printf("Catch streak of %d: +%d%% XP bonus\n", pTrainer.Streak, bonus)
+		}
+		fmt.Printf("You gained %d XP!\n", xp)
 	}
 
 	return nil
 }
 
-// func loadPokedex(filename string) (map[string][]byte, error) {
-//     file, err := os.Open(filename)
-//     if err != nil {
-//         if os.IsNotExist(err) {
-//             return make(map[string][]byte), nil // Return an empty map if the file does not exist
-//         }
-//         return nil, err
-//     }
-//     defer file.Close()
-
-//     var cache map[string][]byte
-//     decoder := json.NewDecoder(file)
-//     err = decoder.Decode(&cache)
-//     if err != nil {
-//         return nil, err
-//     }
-
-//     return cache, nil
-// }
-
-// func savePokedex(filename string, cache map[string][]byte) error {
-//     file, err := os.Create(filename)
-//     if err != nil {
-//         return err
-//     }
-//     defer file.Close()
-
-//     encoder := json.NewEncoder(file)
-//     err = encoder.Encode(cache)
-//     if err != nil {
-//         return err
-//     }
-
-//     return nil
-// }
+// setup applies cfg to the globals the commands use, then loads the disk
+// cache and the profile. main calls it before reading any command.
+func setup(cfg Config) {
+	baseURL = cfg.BaseURL
+	fixtureDir = cfg.FixtureDir
+	mirrors = cfg.Mirrors
+	httpClient.Timeout = cfg.Timeout
+	limiter.interval = cfg.RateLimit
+	requestSlots = newSemaphore(cfg.MaxConcurrency)
+	userAgent = cfg.UserAgent
+	offline = cfg.Offline
+	noColor = cfg.NoColor
+
+	pCache = pokecache.NewCacheFromEmbed(pokecache.Options{
+		MaxBytes: cfg.CacheMaxBytes,
+		Sliding:  cfg.CacheSliding,
+	})
+	pCache.SetTTL(cfg.CacheTTL)
+	pCache.SetDedupe(cfg.CacheDedupe)
+	pCache.SetPrettyJSON(cfg.PrettyJSON)
+	if cfg.Debug {
+		pCache.SetLogger(log.New(os.Stderr, "debug: ", log.LstdFlags))
+	}
+	openDiskCache(cfg.CacheDir)
+	openProfile(defaultProfilePath(), cfg.TrainerName)
+	rng = newRNG(cfg.Seed)
+	currentWeather = pickWeather(rng)
+
+	aliasesPath := cfg.AliasesPath
+	if aliasesPath == "" {
+		aliasesPath = defaultAliasesPath()
+	}
+	aliases, err := loadAliases(aliasesPath)
+	if err != nil {
+		fmt.Println("Error loading aliases:", err)
+	}
+	for _, err := range registerAliases(aliases) {
+		fmt.Println("Error registering alias:", err)
+	}
+}
+
+// openDiskCache points cacheDir at the directory resolveCacheDir picks for
+// override and loads the entries saved there into pCache.
+func openDiskCache(override string) {
+	cacheDir = resolveCacheDir(override, os.UserCacheDir)
+	if cacheDir == "" {
+		return
+	}
+	if _, err := pCache.Load(cacheDir); err != nil {
+		fmt.Println("Error loading disk cache:", err)
+	}
+}
 
 func main() {
-	reader := bufio.NewReader(os.Stdin)
+	cfg, err := LoadConfig(os.Getenv)
+	if err != nil {
+		fmt.Printf("Error in configuration, falling back to defaults:\n%v\n", err)
+	}
+	setup(cfg)
+
+	if len(os.Args) > 1 {
+		// Single-command mode, e.g. "pokedex catch pikachu".
+		err := dispatch(strings.Join(os.Args[1:], " "))
+		flushCache()
+		os.Exit(exitCode(err))
+	}
+
+	hist := loadHistory(defaultHistoryPath())
+	pEditor = newLineEditor(hist)
+	if interactive() {
+		welcome()
+	}
 
+	lastErr, err := runREPL(pEditor)
+	if err != nil {
+		fmt.Println("Error reading input:", err)
+		lastErr = err
+	}
+	endSession(exitCode(lastErr))
+}
+
+// runREPL dispatches the commands read from e until its input ends, either
+// because piped input ran out or the user pressed Ctrl-D. It returns the
+// last command failure, to be reported in the exit status, or readErr when
+// reading failed.
+func runREPL(e *lineEditor) (lastErr, readErr error) {
 	for {
-		fmt.Print("Pokedex> ")
-		input, err := reader.ReadString('\n')
+		input, err := e.ReadLine("Pokedex> ")
+		if errors.Is(err, io.EOF) {
+			return lastErr, nil
+		}
 		if err != nil {
-			fmt.Println("Error reading input:", err)
-			return
+			return lastErr, err
 		}
-		input = strings.TrimSpace(input)
-		parts := strings.Fields(input)
-
-		if len(parts) == 0 {
-			continue
+		// Piped scripts are not what the user typed, keep them out of the
+		// history.
+		if e.terminal {
+			e.history.Add(input)
+			e.history.Save()
+		}
+		if err := dispatch(input); err != nil {
+			lastErr = err
 		}
-		command := parts[0]
-		params := parts[1:]
+	}
+}
 
-		commandEntry, found := commands[command]
+var errUnknownCommand = errors.New("unknown command")
 
-		if found {
-			err := commandEntry.callback(params...)
-			if err != nil {
-				fmt.Println("Error executing command:", err)
-			}
-		} else {
-			fmt.Println("Unknown command")
-		}
+// dispatch runs one line of input through the command map, reporting any
+// problem to the user. It returns the command's error, if any.
+func dispatch(input string) error {
+	parts := strings.Fields(strings.TrimSpace(input))
+	if len(parts) == 0 {
+		return nil
+	}
+	command := parts[0]
+	params := parts[1:]
+
+	commandEntry, found := commands[command]
+	if !found {
+		fmt.Println("Unknown command")
+		return errUnknownCommand
+	}
+	if offline && commandEntry.needsNetwork {
+		fmt.Printf("%s requires network, but offline mode is on. Use 'offline off' to go back online.\n", command)
+		return errOffline
+	}
+	currentSession.CountCommand()
+	err := commandEntry.callback(params...)
+	if err != nil {
+		fmt.Println("Error executing command:", err)
 	}
+	return err
 }
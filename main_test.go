@@ -0,0 +1,672 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ablanchetMD/pokedex/pokecache"
+)
+
+// fakeClock is a pokecache.Clock tests move forward by hand.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// setupTest points the globals commands use at fresh state: an empty
+// pokedex and cache reading the returned clock, no profile or disk cache, and
+// default settings. Nothing leaves the process unless the test stubs it.
+func setupTest(t *testing.T) *fakeClock {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	pCache = pokecache.NewCacheWithClock(clock)
+	pDex = NewPokedex()
+	pDex.clock = clock
+	pTrainer = newTrainer()
+	pTrainer.clock = clock
+	profilePath = ""
+	cacheDir = ""
+	offline = false
+	baseURL = primaryBaseURL
+	mirrors = nil
+	fixtureDir = ""
+	userAgent = defaultUserAgent
+	limiter.interval = 0
+	requestSlots = newSemaphore(defaultConcurrency)
+	SetTransport(nil)
+	rng = rand.New(rand.NewSource(1))
+	currentWeather = weather{name: "clear"}
+	currentSession = &session{start: clock.Now()}
+	pCatchLog = newCatchLog(catchLogSize)
+	pCooldown = &catchCooldown{last: make(map[string]time.Time), clock: clock}
+	pScrollback = newScrollback(defaultScrollbackSize)
+	pEditor = nil
+	lastCatch = nil
+	diceSides = 10
+	catchDelay = 0
+	moveLimit = 10
+	maxResults = 20
+	noColor = false
+	return clock
+}
+
+// captureOutput returns what f prints to stdout.
+func captureOutput(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	defer func() {
+		os.Stdout = stdout
+	}()
+	f()
+	w.Close()
+	return <-done
+}
+
+// stubAPI serves bodies in place of pokeapi: a request for
+// "https://pokeapi.co/api/v2/pokemon/25" is answered with bodies["/pokemon/25"],
+// and one with a query string with e.g. bodies["/type?limit=100"]. Other
+// paths get a 404. It returns how many requests were served.
+func stubAPI(t *testing.T, bodies map[string]string) *atomic.Int64 {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		key := r.URL.Path
+		if r.URL.RawQuery != "" {
+			key += "?" + r.URL.RawQuery
+		}
+		body, ok := bodies[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	baseURL = srv.URL
+	return &requests
+}
+
+// pokemonJSON returns a minimal pokemon body that passes validatePokemon.
+func pokemonJSON(id int, name string, baseExperience int, types ...string) string {
+	typeList := make([]map[string]any, 0, len(types))
+	for i, t := range types {
+		typeList = append(typeList, map[string]any{
+			"slot": i + 1,
+			"type": map[string]string{"name": t, "url": primaryBaseURL + "/type/" + t},
+		})
+	}
+	data, err := json.Marshal(map[string]any{
+		"id":              id,
+		"name":            name,
+		"base_experience": baseExperience,
+		"stats":           []any{},
+		"types":           typeList,
+		"species": map[string]string{
+			"name": name,
+			"url":  fmt.Sprintf("%s/pokemon-species/%d", primaryBaseURL, id),
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// listJSON returns a list page holding names, with an optional next cursor.
+func listJSON(count int, next string, names ...string) string {
+	results := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		results = append(results, map[string]string{"name": name, "url": ""})
+	}
+	page := map[string]any{"count": count, "next": nil, "previous": nil, "results": results}
+	if next != "" {
+		page["next"] = next
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// catchPokemon stores the pokemon described by body in pDex, as a catch would.
+func catchPokemon(t *testing.T, body string) {
+	t.Helper()
+	var pokemon Pokemon
+	if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	pDex.Add(pokemon.Name, []byte(body))
+}
+
+func TestSuggestPokemon(t *testing.T) {
+	setupTest(t)
+	catchPokemon(t, pokemonJSON(1, "bulbasaur", 64, "grass"))
+	catchPokemon(t, pokemonJSON(4, "charmander", 62, "fire"))
+	var list PokeList
+	if err := json.Unmarshal([]byte(listJSON(3, "", "bulbasaur", "charmander", "squirtle")), &list); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		name, ok := suggestPokemon(list, pDex)
+		if !ok || name != "squirtle" {
+			t.Fatalf("suggestPokemon = %q, %v, want squirtle, true", name, ok)
+		}
+	}
+
+	catchPokemon(t, pokemonJSON(7, "squirtle", 63, "water"))
+	if name, ok := suggestPokemon(list, pDex); ok {
+		t.Errorf("suggestPokemon = %q with every pokemon caught, want none", name)
+	}
+}
+
+func TestSuggestPicksOnlyUncaught(t *testing.T) {
+	setupTest(t)
+	catchPokemon(t, pokemonJSON(1, "bulbasaur", 64, "grass"))
+	var list PokeList
+	if err := json.Unmarshal([]byte(listJSON(3, "", "bulbasaur", "charmander", "squirtle")), &list); err != nil {
+		t.Fatal(err)
+	}
+	suggested := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		name, _ := suggestPokemon(list, pDex)
+		suggested[name] = true
+	}
+	if suggested["bulbasaur"] {
+		t.Error("suggested a caught pokemon")
+	}
+	if !suggested["charmander"] || !suggested["squirtle"] {
+		t.Errorf("suggestions %v do not cover both uncaught pokemon", suggested)
+	}
+}
+
+func TestHitRatio(t *testing.T) {
+	tests := []struct {
+		hits, misses uint64
+		want         float64
+	}{
+		{0, 0, 0},
+		{3, 1, 0.75},
+		{0, 4, 0},
+		{5, 0, 1},
+	}
+	for _, tt := range tests {
+		if got := hitRatio(tt.hits, tt.misses); got != tt.want {
+			t.Errorf("hitRatio(%d, %d) = %v, want %v", tt.hits, tt.misses, got, tt.want)
+		}
+	}
+}
+
+func TestInspectForm(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon-form/rotom-wash": `{"id":10059,"name":"rotom-wash","form_name":"wash","is_mega":false,
+			"pokemon":{"name":"rotom-wash","url":""},
+			"types":[{"slot":1,"type":{"name":"electric","url":""}},{"slot":2,"type":{"name":"water","url":""}}]}`,
+	})
+	catchPokemon(t, pokemonJSON(479, "rotom", 154, "electric", "ghost"))
+
+	out := captureOutput(t, func() {
+		if err := commandInspect("rotom", "wash"); err != nil {
+			t.Errorf("inspect rotom wash: %v", err)
+		}
+	})
+	for _, want := range []string{"Name: rotom", "Form: wash", "water"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ghost") {
+		t.Errorf("output still shows the base form's ghost type:\n%s", out)
+	}
+}
+
+func TestInspectUnknownForm(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{})
+	catchPokemon(t, pokemonJSON(479, "rotom", 154, "electric", "ghost"))
+	captureOutput(t, func() {
+		if err := commandInspect("rotom", "nope"); !isNotFound(err) {
+			t.Errorf("inspect rotom nope = %v, want a 404", err)
+		}
+	})
+}
+
+// helpSections splits help output into the command names listed under each
+// category header.
+func helpSections(out string) map[string][]string {
+	sections := make(map[string][]string)
+	header := ""
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "  ") {
+			name, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+			sections[header] = append(sections[header], name)
+		} else if strings.HasSuffix(line, ":") {
+			header = strings.TrimSuffix(line, ":")
+		}
+	}
+	return sections
+}
+
+func TestHelpGroupsByCategory(t *testing.T) {
+	setupTest(t)
+	out := captureOutput(t, func() {
+		commandHelp()
+	})
+	sections := helpSections(out)
+	for _, category := range categories {
+		header := strings.ToUpper(category[:1]) + category[1:]
+		for _, name := range sections[header] {
+			if commands[name].category != category {
+				t.Errorf("%s is listed under %s, want %s", name, header, commands[name].category)
+			}
+		}
+	}
+	for header, want := range map[string]string{"Exploration": "map", "Collection": "catch", "Utility": "help"} {
+		if !strings.Contains(strings.Join(sections[header], " ")+" ", want+" ") {
+			t.Errorf("%s is not listed under %s: %v", want, header, sections[header])
+		}
+	}
+	exploration, collection, utility := strings.Index(out, "Exploration:"), strings.Index(out, "Collection:"), strings.Index(out, "Utility:")
+	if exploration < 0 || exploration > collection || collection > utility {
+		t.Errorf("headers are out of order:\n%s", out)
+	}
+}
+
+func TestHelpListsAliasedCommandOnce(t *testing.T) {
+	setupTest(t)
+	if errs := registerAliases(map[string]string{"c": "catch"}); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	t.Cleanup(func() { delete(commands, "c") })
+
+	out := captureOutput(t, func() {
+		commandHelp()
+	})
+	listed := 0
+	for _, names := range helpSections(out) {
+		for _, name := range names {
+			if name == "c" {
+				t.Error("help lists the alias")
+			}
+			if name == "catch" {
+				listed++
+			}
+		}
+	}
+	if listed != 1 {
+		t.Errorf("catch is listed %d times, want once", listed)
+	}
+}
+
+func TestGetPokemonParsesOnce(t *testing.T) {
+	setupTest(t)
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	first, err := pDex.GetPokemon("pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := pDex.entries["pikachu"].parsed
+	if parsed == nil {
+		t.Fatal("GetPokemon did not keep the parsed pokemon")
+	}
+
+	// Were the raw bytes parsed again, this would fail.
+	entry := pDex.entries["pikachu"]
+	entry.data = []byte("{")
+	pDex.entries["pikachu"] = entry
+	second, err := pDex.GetPokemon("pikachu")
+	if err != nil || second.Name != first.Name || pDex.entries["pikachu"].parsed != parsed {
+		t.Errorf("second GetPokemon = %+v, %v, want the first parse reused", second, err)
+	}
+
+	pDex.Add("pikachu", []byte(pokemonJSON(25, "pikachu", 120, "electric")))
+	if pDex.entries["pikachu"].parsed != nil {
+		t.Error("Add kept the parsed copy of the old data")
+	}
+	refreshed, err := pDex.GetPokemon("pikachu")
+	if err != nil || refreshed.BaseExperience != 120 {
+		t.Errorf("GetPokemon after Add = %+v, %v, want base experience 120", refreshed, err)
+	}
+}
+
+func TestHelpOfflineListsOnlyOfflineCommands(t *testing.T) {
+	setupTest(t)
+	out := captureOutput(t, func() {
+		commandHelp("--offline")
+	})
+	listed := make(map[string]bool)
+	for _, names := range helpSections(out) {
+		for _, name := range names {
+			listed[name] = true
+		}
+	}
+	for name, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if listed[name] == cmd.needsNetwork {
+			t.Errorf("%s: listed %v, needs network %v", name, listed[name], cmd.needsNetwork)
+		}
+	}
+	if !listed["catch"] || listed["ping"] {
+		t.Errorf("help --offline should list catch, which can use the cache, and not ping:\n%s", out)
+	}
+}
+
+func TestDispatchGuardsNetworkCommandsOffline(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{})
+	offline = true
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+
+	for _, input := range []string{"ping", "refresh pikachu"} {
+		out := captureOutput(t, func() {
+			if err := dispatch(input); !errors.Is(err, errOffline) {
+				t.Errorf("dispatch(%q) = %v, want errOffline", input, err)
+			}
+		})
+		if !strings.Contains(out, "requires network") {
+			t.Errorf("dispatch(%q) output lacks the guard message:\n%s", input, out)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("made %d requests while offline", n)
+	}
+
+	// Commands that can be served from the cache are not stopped up front.
+	pCache.Add(primaryBaseURL+"/pokemon/pikachu", []byte(pokemonJSON(25, "pikachu", 112, "electric")))
+	captureOutput(t, func() {
+		if err := dispatch("catchrate pikachu"); err != nil {
+			t.Errorf("catchrate from the cache while offline: %v", err)
+		}
+	})
+}
+
+func TestPokedexRecent(t *testing.T) {
+	clock := setupTest(t)
+	for i, name := range []string{"bulbasaur", "charmander", "squirtle", "pikachu", "eevee", "snorlax"} {
+		catchPokemon(t, pokemonJSON(i+1, name, 50, "normal"))
+		clock.Advance(time.Minute)
+	}
+
+	if got, want := pDex.Recent(3), []string{"snorlax", "eevee", "pikachu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Recent(3) = %v, want %v", got, want)
+	}
+	if got := pDex.Recent(10); len(got) != 6 || got[5] != "bulbasaur" {
+		t.Errorf("Recent(10) = %v, want all six, oldest last", got)
+	}
+
+	out := captureOutput(t, func() {
+		if err := commandPokedex("recent"); err != nil {
+			t.Errorf("pokedex recent: %v", err)
+		}
+	})
+	want := "Recently caught:\n  - snorlax\n  - eevee\n  - pikachu\n  - squirtle\n  - charmander\n"
+	if out != want {
+		t.Errorf("pokedex recent printed\n%s\nwant\n%s", out, want)
+	}
+
+	captureOutput(t, func() {
+		if err := commandPokedex("recent", "0"); err == nil {
+			t.Error("pokedex recent 0 was accepted")
+		}
+	})
+}
+
+func TestPokedexEmpty(t *testing.T) {
+	setupTest(t)
+	out := captureOutput(t, func() {
+		if err := commandPokedex(); err != nil {
+			t.Errorf("pokedex: %v", err)
+		}
+	})
+	if want := "You haven't caught any pokemon yet. Try 'explore' then 'catch'!\n"; out != want {
+		t.Errorf("empty pokedex printed %q, want %q", out, want)
+	}
+
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	out = captureOutput(t, func() {
+		if err := commandPokedex(); err != nil {
+			t.Errorf("pokedex: %v", err)
+		}
+	})
+	if strings.Contains(out, "haven't caught") || !strings.Contains(out, "Pokedex (1 caught):") {
+		t.Errorf("pokedex with one pokemon printed:\n%s", out)
+	}
+}
+
+// locationAreaJSON returns a location-area body in which each of pokemon
+// can be encountered.
+func locationAreaJSON(name string, pokemon ...string) string {
+	encounters := make([]map[string]any, 0, len(pokemon))
+	for _, p := range pokemon {
+		encounters = append(encounters, map[string]any{
+			"pokemon": map[string]string{"name": p, "url": primaryBaseURL + "/pokemon/" + p + "/"},
+		})
+	}
+	data, err := json.Marshal(map[string]any{"id": 1, "name": name, "pokemon_encounters": encounters})
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func TestExploreEmptyEncounters(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/location-area/empty-area":         locationAreaJSON("empty-area"),
+		"/location-area/canalave-city-area": locationAreaJSON("canalave-city-area", "tentacool", "staryu"),
+	})
+
+	out := captureOutput(t, func() {
+		if err := commandExplore("empty-area"); err != nil {
+			t.Errorf("explore: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No pokemon encounters at this location.") || strings.Contains(out, "Pokemon found:") {
+		t.Errorf("explore of an empty area printed:\n%s", out)
+	}
+
+	out = captureOutput(t, func() {
+		commandExplore("canalave-city-area")
+	})
+	if strings.Contains(out, "No pokemon encounters") || !strings.Contains(out, "Pokemon found:\n  - tentacool\n  - staryu\n") {
+		t.Errorf("explore printed:\n%s", out)
+	}
+}
+
+func TestPokedexCountUnderConcurrentAdds(t *testing.T) {
+	setupTest(t)
+	const goroutines, perGoroutine = 8, 50
+	body := []byte(pokemonJSON(25, "pikachu", 112, "electric"))
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				// Every goroutine also re-adds the keys of the one before
+				// it, which must not count twice.
+				pDex.Add(fmt.Sprintf("pokemon-%d-%d", g, i), body)
+				pDex.Add(fmt.Sprintf("pokemon-%d-%d", (g+1)%goroutines, i), body)
+				pDex.Count()
+			}
+		}(g)
+	}
+	wg.Wait()
+	if got, want := pDex.Count(), goroutines*perGoroutine; got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+	if got := len(pDex.Names()); got != pDex.Count() {
+		t.Errorf("Count() = %d but the pokedex holds %d entries", pDex.Count(), got)
+	}
+}
+
+func TestPokedexNamesWhileAdding(t *testing.T) {
+	setupTest(t)
+	body := []byte(pokemonJSON(25, "pikachu", 112, "electric"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			pDex.Add(fmt.Sprintf("pokemon-%03d", i), body)
+		}
+	}()
+	for {
+		names := pDex.Names()
+		if !sort.StringsAreSorted(names) {
+			t.Fatalf("Names() = %v, not sorted", names)
+		}
+		select {
+		case <-done:
+			if got := len(pDex.Names()); got != 200 {
+				t.Errorf("Names() holds %d names, want 200", got)
+			}
+			return
+		default:
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errUnknownCommand, 2},
+		{fmt.Errorf("running %q: %w", "bogus", errUnknownCommand), 2},
+		{errors.New("no Pokemon name provided"), 1},
+		{errOffline, 1},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDispatchErrors(t *testing.T) {
+	setupTest(t)
+	captureOutput(t, func() {
+		if err := dispatch("bogus"); exitCode(err) != 2 {
+			t.Errorf("dispatch(bogus) = %v, want exit status 2", err)
+		}
+		if err := dispatch("inspect"); exitCode(err) != 1 {
+			t.Errorf("dispatch(inspect) = %v, want exit status 1", err)
+		}
+		if err := dispatch("   "); err != nil {
+			t.Errorf("dispatch of a blank line = %v", err)
+		}
+	})
+}
+
+func TestCacheDump(t *testing.T) {
+	clock := setupTest(t)
+	pCache.Add("https://pokeapi.co/api/v2/pokemon/25", []byte("1234567890"))
+	clock.Advance(90 * time.Second)
+	pCache.Add("https://pokeapi.co/api/v2/type/fire", []byte("12345"))
+
+	out := captureOutput(t, func() {
+		commandCacheDump()
+	})
+	want := "      10 B  1m30s    https://pokeapi.co/api/v2/pokemon/25\n" +
+		"       5 B  0s       https://pokeapi.co/api/v2/type/fire\n" +
+		"Total: 15 bytes in 2 entries\n"
+	if out != want {
+		t.Errorf("cachedump printed\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestInspectShowsHeldItems(t *testing.T) {
+	setupTest(t)
+	var pokemon map[string]any
+	if err := json.Unmarshal([]byte(pokemonJSON(113, "chansey", 395, "normal")), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	pokemon["held_items"] = []map[string]any{
+		{"item": map[string]string{"name": "oval-stone", "url": ""}},
+		{"item": map[string]string{"name": "lucky-egg", "url": ""}},
+	}
+	body, err := json.Marshal(pokemon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catchPokemon(t, string(body))
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+
+	for name, want := range map[string]string{
+		"chansey": "Held items: oval-stone, lucky-egg",
+		"pikachu": "Held items: none",
+	} {
+		out := captureOutput(t, func() {
+			if err := commandInspect(name); err != nil {
+				t.Errorf("inspect %s: %v", name, err)
+			}
+		})
+		if !strings.Contains(out, want) {
+			t.Errorf("inspect %s output lacks %q:\n%s", name, want, out)
+		}
+	}
+}
+
+func TestExploreURLs(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/location-area/canalave-city-area": locationAreaJSON("canalave-city-area", "tentacool", "a-very-long-pokemon-name-indeed"),
+	})
+	out := captureOutput(t, func() {
+		if err := commandExplore("canalave-city-area", "--urls"); err != nil {
+			t.Errorf("explore --urls: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"tentacool            https://pokeapi.co/api/v2/pokemon/tentacool/\n",
+		"a-very-long-pokemon… https://pokeapi.co/api/v2/pokemon/a-very-long-pokemon-name-indeed/\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	out = captureOutput(t, func() {
+		commandExplore("canalave-city-area")
+	})
+	if strings.Contains(out, "https://") {
+		t.Errorf("explore without --urls printed URLs:\n%s", out)
+	}
+}
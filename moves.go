@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type Move struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Power       *int   `json:"power"`
+	DamageClass struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"damage_class"`
+}
+
+// moveLimit caps how many moves moves prints. It overrides maxResults for
+// that command.
+var moveLimit = 10
+
+// moveWorkers bounds how many move lookups bestmove runs at once; requests
+// are further limited by requestSlots.
+const moveWorkers = 4
+
+func commandMoves(params ...string) error {
+	limit, params, err := takeLimit(params, moveLimit)
+	if err != nil {
+		return err
+	}
+	if len(params) < 1 {
+		fmt.Println("Please provide a Pokemon name")
+		return errors.New("no Pokemon name provided")
+	}
+	pokemon, err := pDex.GetPokemon(params[0])
+	if err != nil {
+		fmt.Println("You have not caught that pokemon yet (or there was an error):", params[0])
+		return err
+	}
+
+	names := make([]string, 0, len(pokemon.Moves))
+	for _, move := range pokemon.Moves {
+		names = append(names, move.Move.Name)
+	}
+	fmt.Printf("Moves for %s:\n", pokemon.Name)
+	printList(names, limit)
+	return nil
+}
+
+func commandMoveLimit(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Move limit:", moveLimit)
+		return nil
+	}
+	n, err := strconv.Atoi(params[0])
+	if err != nil || n < 1 {
+		fmt.Println("Please provide a positive number")
+		return errors.New("invalid move limit")
+	}
+	moveLimit = n
+	fmt.Println("Move limit set to", moveLimit)
+	return nil
+}
+
+// fetchMoves looks up every url concurrently. Moves that fail to fetch or
+// parse are left out and counted in the second return value.
+func fetchMoves(urls []string) ([]Move, int) {
+	results := make(chan Move, len(urls))
+	failures := make(chan struct{}, len(urls))
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < moveWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range jobs {
+				body, err := fetch(url)
+				if err != nil {
+					failures <- struct{}{}
+					continue
+				}
+				var move Move
+				if err := json.Unmarshal(body, &move); err != nil {
+					failures <- struct{}{}
+					continue
+				}
+				results <- move
+			}
+		}()
+	}
+	for _, url := range urls {
+		jobs <- url
+	}
+	close(jobs)
+	wg.Wait()
+	close(results)
+	close(failures)
+
+	moves := make([]Move, 0, len(urls))
+	for move := range results {
+		moves = append(moves, move)
+	}
+	return moves, len(failures)
+}
+
+// bestMove returns the damaging move with the highest power. Status moves,
+// which have no power, are skipped. Ties go to the alphabetically first.
+func bestMove(moves []Move) (Move, bool) {
+	var best Move
+	found := false
+	for _, move := range moves {
+		if move.Power == nil {
+			continue
+		}
+		if !found || *move.Power > *best.Power || (*move.Power == *best.Power && move.Name < best.Name) {
+			best = move
+			found = true
+		}
+	}
+	return best, found
+}
+
+func commandBestMove(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a Pokemon name")
+		return errors.New("no Pokemon name provided")
+	}
+	pokemon, err := pDex.GetPokemon(params[0])
+	if err != nil {
+		fmt.Println("You have not caught that pokemon yet (or there was an error):", params[0])
+		return err
+	}
+
+	urls := make([]string, 0, len(pokemon.Moves))
+	for _, move := range pokemon.Moves {
+		urls = append(urls, move.Move.URL)
+	}
+	moves, failed := fetchMoves(urls)
+	if failed > 0 {
+		fmt.Printf("Could not look up %d of %d moves.\n", failed, len(urls))
+	}
+	best, ok := bestMove(moves)
+	if !ok {
+		fmt.Println(pokemon.Name, "has no damaging moves.")
+		return nil
+	}
+	fmt.Printf("Best move for %s: %s (power %d)\n", pokemon.Name, best.Name, *best.Power)
+	return nil
+}
+
+// leaderboardSize is how many pokemon movecount ranks by default; a lower
+// maxresults or --limit <n> overrides it.
+const leaderboardSize = 10
+
+type moveCount struct {
+	name  string
+	count int
+}
+
+// rankMoveCounts orders pokemon by how many distinct moves they can learn,
+// most first. Ties go to the alphabetically first.
+func rankMoveCounts(pokemon []Pokemon) []moveCount {
+	counts := make([]moveCount, 0, len(pokemon))
+	for _, p := range pokemon {
+		distinct := make(map[string]bool)
+		for _, move := range p.Moves {
+			distinct[move.Move.Name] = true
+		}
+		counts = append(counts, moveCount{name: p.Name, count: len(distinct)})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].count != counts[j].count {
+			return counts[i].count > counts[j].count
+		}
+		return counts[i].name < counts[j].name
+	})
+	return counts
+}
+
+func commandMoveCount(params ...string) error {
+	limit, _, err := takeLimit(params, min(leaderboardSize, maxResults))
+	if err != nil {
+		return err
+	}
+	keys := pDex.Names()
+	if len(keys) == 0 {
+		fmt.Println("You have not caught any pokemon yet")
+		return nil
+	}
+	pokemon := make([]Pokemon, 0, len(keys))
+	for _, key := range keys {
+		p, err := pDex.GetPokemon(key)
+		if err != nil {
+			fmt.Println("Error reading pokemon:", key, err)
+			return err
+		}
+		pokemon = append(pokemon, p)
+	}
+	fmt.Println("Most moves learnable:")
+	counts := rankMoveCounts(pokemon)
+	lines := make([]string, 0, len(counts))
+	for i, entry := range counts {
+		lines = append(lines, fmt.Sprintf("%2d. %-16s %d", i+1, truncateRunes(entry.name, 16), entry.count))
+	}
+	printRows(lines, limit)
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pokemonWithMoves returns a pokemon body that can learn moves, each
+// pointing at its move URL.
+func pokemonWithMoves(id int, name string, moves ...string) string {
+	var pokemon map[string]any
+	if err := json.Unmarshal([]byte(pokemonJSON(id, name, 100, "normal")), &pokemon); err != nil {
+		panic(err)
+	}
+	list := make([]any, 0, len(moves))
+	for _, move := range moves {
+		list = append(list, map[string]any{
+			"move": map[string]string{"name": move, "url": primaryBaseURL + "/move/" + move},
+		})
+	}
+	pokemon["moves"] = list
+	data, err := json.Marshal(pokemon)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+func moveNames(n int) []string {
+	names := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		names = append(names, fmt.Sprintf("move-%02d", i))
+	}
+	return names
+}
+
+func TestMovesAreCapped(t *testing.T) {
+	tests := []struct {
+		name      string
+		moveLimit int
+		params    []string
+		listed    int
+		footer    string
+	}{
+		{"default limit", 10, nil, 10, "(showing 10 of 15)"},
+		{"movelimit", 4, nil, 4, "(showing 4 of 15)"},
+		{"--limit overrides movelimit", 4, []string{"--limit", "12"}, 12, "(showing 12 of 15)"},
+		{"limit above the move count", 20, nil, 15, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			catchPokemon(t, pokemonWithMoves(137, "porygon", moveNames(15)...))
+			moveLimit = tt.moveLimit
+			out := captureOutput(t, func() {
+				if err := commandMoves(append([]string{"porygon"}, tt.params...)...); err != nil {
+					t.Errorf("moves: %v", err)
+				}
+			})
+			if got := strings.Count(out, "  - move-"); got != tt.listed {
+				t.Errorf("listed %d moves, want %d:\n%s", got, tt.listed, out)
+			}
+			if tt.footer != "" && !strings.Contains(out, tt.footer) {
+				t.Errorf("output lacks footer %q:\n%s", tt.footer, out)
+			}
+			if tt.footer == "" && strings.Contains(out, "showing") {
+				t.Errorf("output has a footer although nothing was cut:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestCommandMoveLimit(t *testing.T) {
+	setupTest(t)
+	captureOutput(t, func() {
+		if err := commandMoveLimit("3"); err != nil {
+			t.Errorf("movelimit 3: %v", err)
+		}
+		if err := commandMoveLimit("0"); err == nil {
+			t.Error("movelimit 0 was accepted")
+		}
+	})
+	if moveLimit != 3 {
+		t.Errorf("moveLimit = %d, want 3", moveLimit)
+	}
+}
+
+func moveJSON(name string, power int) string {
+	if power == 0 {
+		return fmt.Sprintf(`{"id":1,"name":%q,"power":null,"damage_class":{"name":"status","url":""}}`, name)
+	}
+	return fmt.Sprintf(`{"id":1,"name":%q,"power":%d,"damage_class":{"name":"physical","url":""}}`, name, power)
+}
+
+func TestBestMove(t *testing.T) {
+	power := func(p int) *int { return &p }
+	tests := []struct {
+		name   string
+		moves  []Move
+		want   string
+		wantOk bool
+	}{
+		{"none", nil, "", false},
+		{"only status moves", []Move{{Name: "growl"}, {Name: "leer"}}, "", false},
+		{"highest power", []Move{{Name: "tackle", Power: power(40)}, {Name: "growl"}, {Name: "hyper-beam", Power: power(150)}, {Name: "surf", Power: power(90)}}, "hyper-beam", true},
+		{"tie goes to the first name", []Move{{Name: "surf", Power: power(90)}, {Name: "crunch", Power: power(80)}, {Name: "ice-beam", Power: power(90)}}, "ice-beam", true},
+	}
+	for _, tt := range tests {
+		got, ok := bestMove(tt.moves)
+		if got.Name != tt.want || ok != tt.wantOk {
+			t.Errorf("%s: bestMove = %q, %v, want %q, %v", tt.name, got.Name, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestCommandBestMove(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/move/tackle":       moveJSON("tackle", 40),
+		"/move/growl":        moveJSON("growl", 0),
+		"/move/thunderbolt":  moveJSON("thunderbolt", 90),
+		"/move/quick-attack": moveJSON("quick-attack", 40),
+		// "/move/broken" is missing, so its lookup fails.
+	})
+	catchPokemon(t, pokemonWithMoves(25, "pikachu", "tackle", "growl", "thunderbolt", "quick-attack", "broken"))
+
+	out := captureOutput(t, func() {
+		if err := commandBestMove("pikachu"); err != nil {
+			t.Errorf("bestmove: %v", err)
+		}
+	})
+	for _, want := range []string{"Could not look up 1 of 5 moves.", "Best move for pikachu: thunderbolt (power 90)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRankMoveCounts(t *testing.T) {
+	var pokemon []Pokemon
+	for _, body := range []string{
+		pokemonWithMoves(132, "ditto", "transform"),
+		pokemonWithMoves(151, "mew", moveNames(12)...),
+		// Repeated moves (learned by several methods) count once.
+		pokemonWithMoves(25, "pikachu", "thunderbolt", "tackle", "thunderbolt", "growl"),
+		pokemonWithMoves(1, "bulbasaur", "tackle", "growl", "vine-whip"),
+	} {
+		var p Pokemon
+		if err := json.Unmarshal([]byte(body), &p); err != nil {
+			t.Fatal(err)
+		}
+		pokemon = append(pokemon, p)
+	}
+	want := []moveCount{{"mew", 12}, {"bulbasaur", 3}, {"pikachu", 3}, {"ditto", 1}}
+	if got := rankMoveCounts(pokemon); !reflect.DeepEqual(got, want) {
+		t.Errorf("rankMoveCounts = %+v, want %+v", got, want)
+	}
+}
+
+func TestMoveCountLeaderboardIsCapped(t *testing.T) {
+	setupTest(t)
+	for i := 1; i <= leaderboardSize+2; i++ {
+		catchPokemon(t, pokemonWithMoves(i, fmt.Sprintf("pokemon-%02d", i), moveNames(i)...))
+	}
+	out := captureOutput(t, func() {
+		commandMoveCount()
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != leaderboardSize+2 || !strings.HasPrefix(lines[1], " 1. pokemon-12") ||
+		lines[len(lines)-1] != fmt.Sprintf("  (showing %d of %d)", leaderboardSize, leaderboardSize+2) {
+		t.Errorf("movecount printed:\n%s", out)
+	}
+
+	out = captureOutput(t, func() {
+		commandMoveCount("--limit", "3")
+	})
+	if !strings.HasSuffix(out, " 3. pokemon-10       10\n  (showing 3 of 12)\n") {
+		t.Errorf("movecount --limit 3 printed:\n%s", out)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// maxPages bounds how many pages walkPages follows, in case the API keeps
+// handing out Next cursors.
+const maxPages = 100
+
+// pageSize is the number of results pokeapi returns per page by default.
+const pageSize = 20
+
+// newPaginator returns a PokeAPI whose first "next" page is startURL.
+func newPaginator(startURL string) *PokeAPI {
+	return &PokeAPI{BaseURL: startURL, NextURL: &startURL}
+}
+
+// pageURL returns the URL of the given 1-based page of baseURL. The first
+// page is baseURL itself, so it shares the cache entry used by map.
+func pageURL(baseURL string, page int) string {
+	if page == 1 {
+		return baseURL
+	}
+	return fmt.Sprintf("%s?offset=%d&limit=%d", baseURL, (page-1)*pageSize, pageSize)
+}
+
+// commandMapJump fetches the requested page directly and moves the map
+// cursors there.
+func (api *PokeAPI) commandMapJump(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a page number")
+		return errors.New("no page number provided")
+	}
+	page, err := strconv.Atoi(params[0])
+	if err != nil || page < 1 {
+		fmt.Println("Please provide a positive page number")
+		return errors.New("invalid page number")
+	}
+	if api.Count == 0 {
+		body, err := fetchValid(api.BaseURL, validateList)
+		if err != nil {
+			return err
+		}
+		var list PokeList
+		err = json.Unmarshal(body, &list)
+		if err != nil {
+			fmt.Println("Error unmarshalling JSON:", err)
+			return err
+		}
+		api.Count = list.Count
+	}
+	lastPage := (api.Count + pageSize - 1) / pageSize
+	if page > lastPage {
+		fmt.Printf("There are only %d pages\n", lastPage)
+		return errors.New("page out of range")
+	}
+
+	url := pageURL(api.BaseURL, page)
+	body, err := fetchValid(url, validateList)
+	if err != nil {
+		return err
+	}
+	return processResponse(body, api, url)
+}
+
+// resolveCursor resolves a Next or Previous cursor against the URL of the
+// page it came from, so relative cursors work as well as absolute ones. A
+// nil cursor, or one that does not parse, is returned unchanged.
+func resolveCursor(pageURL string, cursor *string) *string {
+	if cursor == nil {
+		return nil
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return cursor
+	}
+	ref, err := url.Parse(*cursor)
+	if err != nil {
+		return cursor
+	}
+	resolved := base.ResolveReference(ref).String()
+	return &resolved
+}
+
+// pageDirection maps the optional "prev" argument of a paging command to the
+// direction understood by commandMap.
+func pageDirection(params []string) string {
+	if len(params) > 0 && params[0] == "prev" {
+		return "prev"
+	}
+	return "next"
+}
+
+// walkPages fetches startURL and every following page, calling visit with
+// the 1-based page number and its contents.
+func walkPages(startURL string, visit func(page int, list PokeList) error) error {
+	next := &startURL
+	for page := 1; next != nil; page++ {
+		if page > maxPages {
+			return fmt.Errorf("stopped after %d pages", maxPages)
+		}
+		body, err := fetchValid(*next, validateList)
+		if err != nil {
+			return err
+		}
+		var list PokeList
+		err = json.Unmarshal(body, &list)
+		if err != nil {
+			fmt.Println("Error unmarshalling JSON:", err)
+			return err
+		}
+		err = visit(page, list)
+		if err != nil {
+			return err
+		}
+		next = resolveCursor(*next, list.Next)
+	}
+	return nil
+}
+
+// locationNames returns the name of every location-area, page by page.
+func locationNames() ([]string, error) {
+	names := make([]string, 0)
+	err := walkPages(locationAreaURL, func(page int, list PokeList) error {
+		for _, result := range list.Results {
+			names = append(names, result.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error walking locations:", err)
+	}
+	return names, err
+}
+
+func commandMapAll(params ...string) error {
+	limit, params, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	names, err := locationNames()
+	if err != nil {
+		return err
+	}
+
+	if len(params) < 1 {
+		printList(names, limit)
+		return nil
+	}
+	err = writeNameList(params[0], names)
+	if err != nil {
+		fmt.Println("Error writing file:", err)
+		return err
+	}
+	fmt.Printf("Wrote %d locations to %s\n", len(names), params[0])
+	return nil
+}
+
+func commandMapFind(params ...string) error {
+	limit, params, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	if len(params) < 1 {
+		fmt.Println("Please provide part of a location name")
+		return errors.New("no search text provided")
+	}
+	part := strings.ToLower(params[0])
+	found := make([]string, 0)
+	err = walkPages(locationAreaURL, func(page int, list PokeList) error {
+		names := make([]string, 0, len(list.Results))
+		for _, result := range list.Results {
+			names = append(names, result.Name)
+		}
+		for _, name := range matchSubstring(names, part) {
+			found = append(found, fmt.Sprintf("page %3d: %s", page, name))
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error walking locations:", err)
+		return err
+	}
+	fmt.Printf("Found %d locations containing %q\n", len(found), params[0])
+	printList(found, limit)
+	return nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// stubLocationPages serves three pages of location-areas.
+func stubLocationPages(t *testing.T) {
+	t.Helper()
+	stubAPI(t, map[string]string{
+		"/location-area":                  listJSON(5, primaryBaseURL+"/location-area?offset=2&limit=2", "canalave-city-area", "eterna-city-area"),
+		"/location-area?offset=2&limit=2": listJSON(5, primaryBaseURL+"/location-area?offset=4&limit=2", "pastoria-city-area", "eterna-forest-area"),
+		"/location-area?offset=4&limit=2": listJSON(5, "", "mt-coronet-1f"),
+	})
+}
+
+func TestLocationNamesWalksEveryPage(t *testing.T) {
+	setupTest(t)
+	stubLocationPages(t)
+	names, err := locationNames()
+	if err != nil {
+		t.Fatalf("locationNames: %v", err)
+	}
+	want := []string{"canalave-city-area", "eterna-city-area", "pastoria-city-area", "eterna-forest-area", "mt-coronet-1f"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("locationNames() = %v, want %v", names, want)
+	}
+}
+
+func TestMapAllWritesFile(t *testing.T) {
+	setupTest(t)
+	stubLocationPages(t)
+	path := filepath.Join(t.TempDir(), "locations.txt")
+	captureOutput(t, func() {
+		if err := commandMapAll(path); err != nil {
+			t.Errorf("mapall: %v", err)
+		}
+	})
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Fields(string(data)); len(lines) != 5 {
+		t.Errorf("wrote %d locations, want 5:\n%s", len(lines), data)
+	}
+}
+
+func TestWalkPagesStopsAtMaxPages(t *testing.T) {
+	setupTest(t)
+	// The only page points back at itself.
+	stubAPI(t, map[string]string{
+		"/location-area": listJSON(1, primaryBaseURL+"/location-area", "loop"),
+	})
+	captureOutput(t, func() {
+		if _, err := locationNames(); err == nil {
+			t.Error("locationNames followed a cursor loop without failing")
+		}
+	})
+}
+
+func TestBrowseItemsAndBerries(t *testing.T) {
+	for _, resource := range []struct {
+		path          string
+		first, second string
+	}{
+		{"/item", "master-ball", "potion"},
+		{"/berry", "cheri", "pecha"},
+	} {
+		setupTest(t)
+		second := resource.path + "?offset=1&limit=1"
+		stubAPI(t, map[string]string{
+			resource.path: fmt.Sprintf(`{"count":2,"next":%q,"previous":null,"results":[{"name":%q,"url":""}]}`,
+				primaryBaseURL+second, resource.first),
+			second: fmt.Sprintf(`{"count":2,"next":null,"previous":%q,"results":[{"name":%q,"url":""}]}`,
+				primaryBaseURL+resource.path+"?offset=0&limit=1", resource.second),
+			resource.path + "?offset=0&limit=1": fmt.Sprintf(`{"count":2,"next":%q,"previous":null,"results":[{"name":%q,"url":""}]}`,
+				primaryBaseURL+second, resource.first),
+		})
+		api := newPaginator(primaryBaseURL + resource.path)
+
+		var pages []string
+		for _, dir := range []string{"next", "next", "next", "prev"} {
+			pages = append(pages, strings.TrimSpace(captureOutput(t, func() {
+				if err := api.commandMap(dir); err != nil {
+					t.Errorf("%s %s: %v", resource.path, dir, err)
+				}
+			})))
+		}
+		want := []string{resource.first, resource.second, "No more results", resource.first}
+		if !reflect.DeepEqual(pages, want) {
+			t.Errorf("%s pages = %q, want %q", resource.path, pages, want)
+		}
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	tests := []struct {
+		page int
+		want string
+	}{
+		{1, locationAreaURL},
+		{2, locationAreaURL + "?offset=20&limit=20"},
+		{20, locationAreaURL + "?offset=380&limit=20"},
+	}
+	for _, tt := range tests {
+		if got := pageURL(locationAreaURL, tt.page); got != tt.want {
+			t.Errorf("pageURL(%d) = %q, want %q", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestMapJump(t *testing.T) {
+	setupTest(t)
+	var requested []string
+	bodies := map[string]string{
+		"/location-area": listJSON(45, primaryBaseURL+"/location-area?offset=20&limit=20", "canalave-city-area"),
+		"/location-area?offset=40&limit=20": fmt.Sprintf(`{"count":45,"next":null,"previous":%q,"results":[{"name":"sendoff-spring-area","url":""}]}`,
+			primaryBaseURL+"/location-area?offset=20&limit=20"),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.RequestURI())
+		body, ok := bodies[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	baseURL = srv.URL
+	api := newPaginator(locationAreaURL)
+
+	out := captureOutput(t, func() {
+		if err := api.commandMapJump("3"); err != nil {
+			t.Errorf("mapjump 3: %v", err)
+		}
+	})
+	if want := []string{"/location-area", "/location-area?offset=40&limit=20"}; !reflect.DeepEqual(requested, want) {
+		t.Errorf("requested %v, want the first page for the count, then %v", requested, want[1])
+	}
+	if strings.TrimSpace(out) != "sendoff-spring-area" {
+		t.Errorf("mapjump printed:\n%s", out)
+	}
+	if api.NextURL != nil || api.PrevURL == nil || *api.PrevURL != primaryBaseURL+"/location-area?offset=20&limit=20" {
+		t.Errorf("cursors after mapjump: next %v, prev %v", api.NextURL, api.PrevURL)
+	}
+
+	captureOutput(t, func() {
+		for _, page := range []string{"4", "0", "x"} {
+			if err := api.commandMapJump(page); err == nil {
+				t.Errorf("mapjump %s was accepted with 3 pages", page)
+			}
+		}
+	})
+	if len(requested) != 2 {
+		t.Errorf("out of range jumps made requests: %v", requested[2:])
+	}
+}
+
+func TestMapFindAcrossPages(t *testing.T) {
+	setupTest(t)
+	stubLocationPages(t)
+	out := captureOutput(t, func() {
+		if err := commandMapFind("ETERNA"); err != nil {
+			t.Errorf("mapfind ETERNA: %v", err)
+		}
+	})
+	want := "Found 2 locations containing \"ETERNA\"\n  - page   1: eterna-city-area\n  - page   2: eterna-forest-area\n"
+	if out != want {
+		t.Errorf("mapfind printed\n%s\nwant\n%s", out, want)
+	}
+
+	out = captureOutput(t, func() {
+		if err := commandMapFind("cinnabar"); err != nil {
+			t.Errorf("mapfind cinnabar: %v", err)
+		}
+	})
+	if !strings.HasPrefix(out, "Found 0 locations") {
+		t.Errorf("mapfind with no match printed:\n%s", out)
+	}
+	captureOutput(t, func() {
+		if err := commandMapFind(); err == nil {
+			t.Error("mapfind without text succeeded")
+		}
+	})
+}
+
+func TestMapFindStopsOnError(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/location-area": listJSON(5, primaryBaseURL+"/location-area?offset=2&limit=2", "canalave-city-area", "eterna-city-area"),
+	})
+	captureOutput(t, func() {
+		if err := commandMapFind("eterna"); !isNotFound(err) {
+			t.Errorf("mapfind with a missing page = %v, want a 404", err)
+		}
+	})
+}
+
+func TestResolveCursor(t *testing.T) {
+	page := primaryBaseURL + "/location-area?offset=20&limit=20"
+	tests := []struct {
+		cursor string
+		want   string
+	}{
+		{primaryBaseURL + "/location-area?offset=40&limit=20", primaryBaseURL + "/location-area?offset=40&limit=20"},
+		{"/api/v2/location-area?offset=40&limit=20", primaryBaseURL + "/location-area?offset=40&limit=20"},
+		{"?offset=40&limit=20", primaryBaseURL + "/location-area?offset=40&limit=20"},
+		{"location-area?offset=0&limit=20", primaryBaseURL + "/location-area?offset=0&limit=20"},
+		{"https://mirror.example/api/v2/location-area?offset=40", "https://mirror.example/api/v2/location-area?offset=40"},
+	}
+	for _, tt := range tests {
+		cursor := tt.cursor
+		got := resolveCursor(page, &cursor)
+		if got == nil || *got != tt.want {
+			t.Errorf("resolveCursor(%q) = %v, want %q", tt.cursor, got, tt.want)
+		}
+	}
+	if got := resolveCursor(page, nil); got != nil {
+		t.Errorf("resolveCursor(nil) = %q, want nil", *got)
+	}
+	bad := "%zz"
+	if got := resolveCursor(page, &bad); got != &bad {
+		t.Errorf("resolveCursor of an unparsable cursor = %v, want it unchanged", got)
+	}
+}
+
+func TestWalkPagesFollowsRelativeCursors(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/location-area":                  listJSON(5, "/api/v2/location-area?offset=2&limit=2", "canalave-city-area", "eterna-city-area"),
+		"/location-area?offset=2&limit=2": listJSON(5, "?offset=4&limit=2", "pastoria-city-area", "eterna-forest-area"),
+		"/location-area?offset=4&limit=2": listJSON(5, "", "mt-coronet-1f"),
+	})
+	names, err := locationNames()
+	if err != nil {
+		t.Fatalf("locationNames: %v", err)
+	}
+	if len(names) != 5 || names[4] != "mt-coronet-1f" {
+		t.Errorf("locationNames() = %v, want all three pages", names)
+	}
+}
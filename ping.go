@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+// pingTimeout bounds how long ping waits for each host to answer.
+const pingTimeout = 5 * time.Second
+
+// ping sends a HEAD request to url and returns the round-trip time. The
+// clock starts once the request gets a connection, so time spent waiting on
+// the rate limiter does not count.
+func ping(url string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	start := time.Now()
+	trace := &httptrace.ClientTrace{GetConn: func(string) { start = time.Now() }}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, trace), http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := sendRequest(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return 0, fmt.Errorf("server returned %s", resp.Status)
+	}
+	return time.Since(start), nil
+}
+
+// pingTargets returns the hosts ping checks: the base URL requests go to,
+// then each mirror.
+func pingTargets() []string {
+	return append([]string{baseURL}, mirrors...)
+}
+
+func commandPing(params ...string) error {
+	if strings.HasPrefix(baseURL, "file://") {
+		fmt.Println("Serving fixtures from", fixtureDir+", no network to check")
+		return nil
+	}
+	var firstErr error
+	reachable := 0
+	for _, target := range pingTargets() {
+		latency, err := ping(target + "/")
+		if err != nil {
+			fmt.Printf("%s is unreachable: %v\n", target, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		fmt.Printf("%s is reachable (%s)\n", target, latency.Round(time.Millisecond))
+		reachable++
+	}
+	if reachable == 0 {
+		return firstErr
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	setupTest(t)
+	var method, path, agent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, agent = r.Method, r.URL.Path, r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	sent := remoteRequests.Load()
+	if _, err := ping(srv.URL + "/"); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if method != http.MethodHead || path != "/" || agent != userAgent {
+		t.Errorf("server saw %s %s from %q, want HEAD / from %q", method, path, agent, userAgent)
+	}
+	if n := remoteRequests.Load() - sent; n != 1 {
+		t.Errorf("counted %d requests for the ping, want 1", n)
+	}
+	if len(requestSlots) != 0 {
+		t.Errorf("ping still holds %d request slots", len(requestSlots))
+	}
+}
+
+func TestPingServerError(t *testing.T) {
+	setupTest(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	if _, err := ping(srv.URL + "/"); err == nil {
+		t.Error("ping succeeded against a server answering 502")
+	}
+}
+
+func TestCommandPingChecksBaseURLAndMirrors(t *testing.T) {
+	setupTest(t)
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	baseURL = down.URL
+	mirrors = []string{up.URL}
+	out := captureOutput(t, func() {
+		if err := commandPing(); err != nil {
+			t.Errorf("ping with a reachable mirror: %v", err)
+		}
+	})
+	if !strings.Contains(out, down.URL+" is unreachable") || !strings.Contains(out, up.URL+" is reachable") {
+		t.Errorf("output does not report both hosts:\n%s", out)
+	}
+
+	mirrors = nil
+	captureOutput(t, func() {
+		if err := commandPing(); err == nil {
+			t.Error("ping succeeded with every host down")
+		}
+	})
+}
+
+func TestCommandPingSkipsFixtures(t *testing.T) {
+	setupTest(t)
+	fixtureDir = t.TempDir()
+	baseURL = "file://" + fixtureDir
+	out := captureOutput(t, func() {
+		if err := commandPing(); err != nil {
+			t.Errorf("ping with fixtures: %v", err)
+		}
+	})
+	if !strings.Contains(out, "no network to check") {
+		t.Errorf("output:\n%s", out)
+	}
+}
@@ -0,0 +1,18 @@
+package pokecache
+
+import "time"
+
+// Clock tells the current time. Expiry checks go through it so tests can
+// control time instead of sleeping.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// RealClock is the Clock backed by time.Now.
+var RealClock Clock = realClock{}
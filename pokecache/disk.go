@@ -0,0 +1,214 @@
+package pokecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// aliasesFile is the file in a Flush directory holding the aliases, as a map
+// of each alias to the key it points at. Entry file names never clash with
+// it.
+const aliasesFile = "aliases.json"
+
+// fileName maps a cache key to the name of its file on disk.
+func fileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// Flush writes every entry to its own file in dir. Each file is written to a
+// temporary name and renamed into place, so a flush interrupted by ctx never
+// leaves a partial file behind. It returns how many entries were persisted;
+// on cancellation that is the number written before ctx was done. The
+// aliases are written to aliasesFile once every entry is. Files are indented
+// when SetPrettyJSON is on, and compact otherwise.
+func (c *Cache) Flush(ctx context.Context, dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	pretty := c.PrettyJSON()
+	written := 0
+	for _, entry := range c.Snapshot() {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		data, err := marshalEntry(entry, pretty)
+		if err != nil {
+			return written, err
+		}
+		if err := writeFileAtomic(ctx, filepath.Join(dir, fileName(entry.Key)), data); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, c.flushAliases(ctx, dir, pretty)
+}
+
+// flushAliases writes the aliases of cached entries to aliasesFile in dir,
+// or removes the file when there are none.
+func (c *Cache) flushAliases(ctx context.Context, dir string, pretty bool) error {
+	c.mu.Lock()
+	aliases := make(map[string]string, len(c.aliases))
+	for alias, key := range c.aliases {
+		if _, ok := c.entries[key]; ok {
+			aliases[alias] = key
+		}
+	}
+	c.mu.Unlock()
+
+	path := filepath.Join(dir, aliasesFile)
+	if len(aliases) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var data []byte
+	var err error
+	if pretty {
+		data, err = json.MarshalIndent(aliases, "", "  ")
+	} else {
+		data, err = json.Marshal(aliases)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ctx, path, data)
+}
+
+// marshalEntry encodes entry as written by Flush.
+func marshalEntry(entry Entry, pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(entry, "", "  ")
+	}
+	return json.Marshal(entry)
+}
+
+func writeFileAtomic(ctx context.Context, path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".flush-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load reads entries written by Flush from dir, skipping expired ones and
+// files that cannot be parsed, then the aliases of the entries it holds. It
+// returns the number of entries loaded.
+func (c *Cache) Load(dir string) (int, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	loaded := 0
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") || file.Name() == aliasesFile {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		if c.expired(entry.CreatedAt, entry.TTL) {
+			continue
+		}
+		if existing, ok := c.entries[entry.Key]; ok && existing.pinned {
+			continue
+		}
+		c.put(entry.Key, cacheEntry{
+			createdAt: entry.CreatedAt,
+			data:      entry.Data,
+			ttl:       entry.TTL,
+		})
+		loaded++
+	}
+	c.loadAliases(dir)
+	return loaded, nil
+}
+
+// loadAliases restores the aliases saved in dir whose key is cached. A
+// missing or unreadable aliases file is ignored. Callers hold c.mu.
+func (c *Cache) loadAliases(dir string) {
+	data, err := os.ReadFile(filepath.Join(dir, aliasesFile))
+	if err != nil {
+		return
+	}
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return
+	}
+	for alias, key := range aliases {
+		if _, ok := c.entries[key]; !ok {
+			continue
+		}
+		if _, ok := c.entries[alias]; ok {
+			continue
+		}
+		c.aliases[alias] = key
+	}
+}
+
+// Validate checks every cache file in dir: it must parse as an entry whose
+// key matches the file name and whose data is valid JSON. It returns how
+// many files are valid and the names of the corrupt ones, which are deleted
+// when remove is set.
+func Validate(dir string, remove bool) (int, []string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+	valid := 0
+	corrupt := make([]string, 0)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") || file.Name() == aliasesFile {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return valid, corrupt, err
+		}
+		var entry Entry
+		if json.Unmarshal(data, &entry) == nil && fileName(entry.Key) == file.Name() && json.Valid(entry.Data) {
+			valid++
+			continue
+		}
+		corrupt = append(corrupt, file.Name())
+		if remove {
+			if err := os.Remove(path); err != nil {
+				return valid, corrupt, err
+			}
+		}
+	}
+	return valid, corrupt, nil
+}
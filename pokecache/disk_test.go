@@ -0,0 +1,193 @@
+package pokecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// expiringContext is a context whose deadline passes after its Err method
+// has been asked a given number of times, standing in for a disk slow
+// enough that the flush runs out of time partway through.
+type expiringContext struct {
+	context.Context
+	checks int
+}
+
+func (c *expiringContext) Err() error {
+	if c.checks <= 0 {
+		return context.DeadlineExceeded
+	}
+	c.checks--
+	return nil
+}
+
+func filledCache(n int) *Cache {
+	c := NewCacheWithClock(newFakeClock())
+	for i := 0; i < n; i++ {
+		c.Add(fmt.Sprintf("key-%d", i), []byte(fmt.Sprintf(`{"n":%d}`, i)))
+	}
+	return c
+}
+
+func cacheFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	return names
+}
+
+func TestFlushWritesEveryEntry(t *testing.T) {
+	dir := t.TempDir()
+	c := filledCache(5)
+	n, err := c.Flush(context.Background(), dir)
+	if err != nil || n != 5 {
+		t.Fatalf("Flush = %d, %v, want 5, nil", n, err)
+	}
+
+	loaded := NewCacheWithClock(newFakeClock())
+	if n, err := loaded.Load(dir); err != nil || n != 5 {
+		t.Errorf("Load = %d, %v, want 5, nil", n, err)
+	}
+}
+
+func TestFlushPersistsAliases(t *testing.T) {
+	dir := t.TempDir()
+	c := filledCache(2)
+	c.AddAlias("alias-0", "key-0")
+	c.AddAlias("dangling", "key-9")
+	if _, err := c.Flush(context.Background(), dir); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	loaded := NewCacheWithClock(newFakeClock())
+	if n, err := loaded.Load(dir); n != 2 || err != nil {
+		t.Fatalf("Load = %d, %v, want the 2 entries", n, err)
+	}
+	if data, err := loaded.Get("alias-0"); err != nil || string(data) != `{"n":0}` {
+		t.Errorf("Get(alias-0) after Load = %q, %v", data, err)
+	}
+	if !reflect.DeepEqual(loaded.aliases, map[string]string{"alias-0": "key-0"}) {
+		t.Errorf("loaded aliases %v, want only the one with an entry", loaded.aliases)
+	}
+	if valid, corrupt, err := Validate(dir, false); valid != 2 || len(corrupt) != 0 || err != nil {
+		t.Errorf("Validate = %d, %v, %v, want the aliases file skipped", valid, corrupt, err)
+	}
+}
+
+func TestFlushTimesOutPartway(t *testing.T) {
+	// Each entry checks the context before it is written and again before
+	// its file is renamed into place.
+	for _, checks := range []int{4, 5} {
+		t.Run(fmt.Sprint(checks, " checks"), func(t *testing.T) {
+			dir := t.TempDir()
+			c := filledCache(5)
+			ctx := &expiringContext{Context: context.Background(), checks: checks}
+			n, err := c.Flush(ctx, dir)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Flush error = %v, want a deadline error", err)
+			}
+			if n != 2 {
+				t.Errorf("Flush persisted %d entries, want 2", n)
+			}
+			files := cacheFiles(t, dir)
+			if len(files) != 2 {
+				t.Errorf("dir holds %v, want the 2 written files", files)
+			}
+			for _, name := range files {
+				if strings.HasPrefix(name, ".flush-") {
+					t.Errorf("left a temporary file behind: %s", name)
+				}
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil || !strings.Contains(string(data), `"key":"key-`) {
+					t.Errorf("%s is not a complete entry: %s, %v", name, data, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFlushWithExpiredContext(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	n, err := filledCache(3).Flush(ctx, dir)
+	if n != 0 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush = %d, %v, want 0 and a deadline error", n, err)
+	}
+}
+
+func TestValidateFindsCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := filledCache(4).Flush(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	files := cacheFiles(t, dir)
+	// A truncated write, a file renamed away from its key and a body that
+	// is not JSON.
+	truncated := files[0]
+	data, err := os.ReadFile(filepath.Join(dir, truncated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, truncated), data[:len(data)/2], 0600); err != nil {
+		t.Fatal(err)
+	}
+	moved := "0000.json"
+	if err := os.Rename(filepath.Join(dir, files[1]), filepath.Join(dir, moved)); err != nil {
+		t.Fatal(err)
+	}
+	notJSON := NewCacheWithClock(newFakeClock())
+	notJSON.Add("raw", []byte("not json"))
+	if _, err := notJSON.Flush(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	// Other files are none of Validate's business.
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hi"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	valid, corrupt, err := Validate(dir, false)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	want := []string{moved, truncated, fileName("raw")}
+	sort.Strings(want)
+	sort.Strings(corrupt)
+	if valid != 2 || !reflect.DeepEqual(corrupt, want) {
+		t.Errorf("Validate = %d valid, corrupt %v, want 2 and %v", valid, corrupt, want)
+	}
+	if n := len(cacheFiles(t, dir)); n != 6 {
+		t.Errorf("Validate without remove left %d files, want 6", n)
+	}
+
+	if _, _, err := Validate(dir, true); err != nil {
+		t.Fatalf("Validate with remove: %v", err)
+	}
+	valid, corrupt, err = Validate(dir, false)
+	if err != nil || valid != 2 || len(corrupt) != 0 {
+		t.Errorf("Validate after removing = %d valid, corrupt %v, %v", valid, corrupt, err)
+	}
+	if n := len(cacheFiles(t, dir)); n != 3 {
+		t.Errorf("%d files left after removing the corrupt ones, want 3", n)
+	}
+}
+
+func TestValidateMissingDir(t *testing.T) {
+	valid, corrupt, err := Validate(filepath.Join(t.TempDir(), "missing"), false)
+	if valid != 0 || len(corrupt) != 0 || err != nil {
+		t.Errorf("Validate of a missing dir = %d, %v, %v, want nothing", valid, corrupt, err)
+	}
+}
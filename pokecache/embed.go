@@ -0,0 +1,33 @@
+package pokecache
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+// dataset.json maps pokeapi URLs to their response bodies. It only holds a
+// handful of pokemon and locations, enough for the core commands offline.
+//
+//go:embed dataset.json
+var embeddedDataset []byte
+
+// NewCacheFromEmbed returns a cache configured by opts, preloaded with the
+// bundled dataset. Embedded entries are pinned so the reaper never drops
+// them.
+func NewCacheFromEmbed(opts Options) *Cache {
+	c := NewCacheWithOptions(opts)
+	var dataset map[string]json.RawMessage
+	if err := json.Unmarshal(embeddedDataset, &dataset); err != nil {
+		panic("pokecache: invalid embedded dataset: " + err.Error())
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for url, body := range dataset {
+		c.put(url, cacheEntry{
+			createdAt: c.clock.Now(),
+			data:      body,
+			pinned:    true,
+		})
+	}
+	return c
+}
@@ -0,0 +1,55 @@
+package pokecache
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewCacheFromEmbed(t *testing.T) {
+	var dataset map[string]json.RawMessage
+	if err := json.Unmarshal(embeddedDataset, &dataset); err != nil {
+		t.Fatalf("embedded dataset does not parse: %v", err)
+	}
+	if len(dataset) == 0 {
+		t.Fatal("embedded dataset is empty")
+	}
+
+	c := NewCacheFromEmbed(Options{})
+	for url := range dataset {
+		data, err := c.Get(url)
+		if err != nil {
+			t.Errorf("Get(%q): %v", url, err)
+			continue
+		}
+		if !json.Valid(data) {
+			t.Errorf("Get(%q) returned invalid JSON", url)
+		}
+	}
+	for _, info := range c.Entries() {
+		if !info.Pinned {
+			t.Errorf("embedded entry %q is not pinned", info.Key)
+		}
+	}
+}
+
+func TestEmbeddedEntriesSurviveReap(t *testing.T) {
+	c := NewCacheFromEmbed(Options{})
+	before := len(c.Entries())
+	c.SetTTL(0)
+	c.Reap()
+	if after := len(c.Entries()); after != before {
+		t.Errorf("Reap dropped embedded entries: %d before, %d after", before, after)
+	}
+}
+
+func TestEmbeddedEntriesAreNeverEvicted(t *testing.T) {
+	c := NewCacheFromEmbed(Options{MaxBytes: 1})
+	before := len(c.Entries())
+	c.Add("extra", []byte("{}"))
+	if after := len(c.Entries()); after != before {
+		t.Errorf("cache holds %d entries, want the %d embedded ones", after, before)
+	}
+	if c.Contains("extra") {
+		t.Error("kept an unpinned entry over the byte budget")
+	}
+}
@@ -0,0 +1,132 @@
+package pokecache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+)
+
+// Entry is an exported copy of a cache entry.
+type Entry struct {
+	Key       string        `json:"key"`
+	Data      []byte        `json:"data"`
+	CreatedAt time.Time     `json:"created_at"`
+	TTL       time.Duration `json:"ttl,omitempty"`
+}
+
+// storedEntry is how an Entry is written out. A body holding a JSON object
+// or array is embedded as is, so files stay readable; any other body is a
+// base64 string, as older versions wrote every body.
+type storedEntry struct {
+	Key       string          `json:"key"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+	TTL       time.Duration   `json:"ttl,omitempty"`
+}
+
+func (e Entry) MarshalJSON() ([]byte, error) {
+	stored := storedEntry{Key: e.Key, CreatedAt: e.CreatedAt, TTL: e.TTL}
+	trimmed := bytes.TrimSpace(e.Data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		stored.Data = trimmed
+	} else {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			return nil, err
+		}
+		stored.Data = data
+	}
+	return json.Marshal(stored)
+}
+
+func (e *Entry) UnmarshalJSON(data []byte) error {
+	var stored storedEntry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	e.Key, e.CreatedAt, e.TTL = stored.Key, stored.CreatedAt, stored.TTL
+	e.Data = nil
+	if len(stored.Data) == 0 || stored.Data[0] != '{' && stored.Data[0] != '[' {
+		return json.Unmarshal(stored.Data, &e.Data)
+	}
+	// Bodies are held compact in memory, however they were written.
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, stored.Data); err != nil {
+		return err
+	}
+	e.Data = compact.Bytes()
+	return nil
+}
+
+// Snapshot returns a copy of every entry currently in the cache, sorted by
+// key so that exports of the same data are byte-identical.
+func (c *Cache) Snapshot() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, 0, len(c.entries))
+	for key, entry := range c.entries {
+		entries = append(entries, Entry{
+			Key:       key,
+			Data:      entry.data,
+			CreatedAt: entry.createdAt,
+			TTL:       entry.ttl,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// Export writes a snapshot of the cache to w as gzipped JSON, indented when
+// SetPrettyJSON is on.
+func (c *Cache) Export(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+	if c.PrettyJSON() {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(c.Snapshot()); err != nil {
+		gz.Close()
+		return err
+	}
+	return gz.Close()
+}
+
+// Import reads gzipped JSON written by Export and adds its entries to the
+// cache, keeping their original timestamps. Expired entries are skipped.
+// It returns the number of entries imported.
+func (c *Cache) Import(r io.Reader) (int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer gz.Close()
+
+	var entries []Entry
+	if err := json.NewDecoder(gz).Decode(&entries); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	imported := 0
+	for _, entry := range entries {
+		if c.expired(entry.CreatedAt, entry.TTL) {
+			continue
+		}
+		if existing, ok := c.entries[entry.Key]; ok && existing.pinned {
+			continue
+		}
+		c.put(entry.Key, cacheEntry{
+			createdAt: entry.CreatedAt,
+			data:      entry.Data,
+			ttl:       entry.TTL,
+		})
+		imported++
+	}
+	return imported, nil
+}
@@ -0,0 +1,177 @@
+package pokecache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	clock := newFakeClock()
+	src := NewCacheWithClock(clock)
+	src.Add("https://pokeapi.co/api/v2/pokemon/25", []byte(`{"id":25,"name":"pikachu"}`))
+	src.AddWithTTL("https://pokeapi.co/api/v2/location-area", []byte(`{"count":1,"results":[]}`), time.Hour)
+	src.Add("raw", []byte("not json"))
+
+	var buf bytes.Buffer
+	if err := src.Export(&buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	dst := NewCacheWithClock(clock)
+	n, err := dst.Import(&buf)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Import returned %d, want 3", n)
+	}
+
+	want := src.Snapshot()
+	got := dst.Snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("imported %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Key != want[i].Key || !bytes.Equal(got[i].Data, want[i].Data) ||
+			!got[i].CreatedAt.Equal(want[i].CreatedAt) || got[i].TTL != want[i].TTL {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestImportSkipsExpiredEntries(t *testing.T) {
+	clock := newFakeClock()
+	src := NewCacheWithClock(clock)
+	src.Add("old", []byte(`{}`))
+	clock.Advance(4 * time.Minute)
+	src.Add("fresh", []byte(`{}`))
+
+	var buf bytes.Buffer
+	if err := src.Export(&buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+	dst := NewCacheWithClock(clock)
+	n, err := dst.Import(&buf)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Import returned %d, want 1", n)
+	}
+	if dst.Contains("old") {
+		t.Error("expired entry was imported")
+	}
+	if !dst.Contains("fresh") {
+		t.Error("fresh entry was not imported")
+	}
+}
+
+func TestExportIsByteIdentical(t *testing.T) {
+	clock := newFakeClock()
+	keys := []string{"c", "a", "d", "b"}
+	export := func(keys []string) []byte {
+		c := NewCacheWithClock(clock)
+		for _, key := range keys {
+			c.Add(key, []byte(`{"key":"`+key+`"}`))
+		}
+		var buf bytes.Buffer
+		if err := c.Export(&buf); err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	first := export(keys)
+	if again := export(keys); !bytes.Equal(again, first) {
+		t.Error("exporting the same data twice gave different bytes")
+	}
+	reversed := []string{"b", "d", "a", "c"}
+	if other := export(reversed); !bytes.Equal(other, first) {
+		t.Error("insertion order changed the export")
+	}
+}
+
+func TestPrettyAndCompactRoundTrip(t *testing.T) {
+	clock := newFakeClock()
+	body := []byte(`{"id":25,"name":"pikachu","types":[{"slot":1}]}`)
+	var sizes []int
+	for _, pretty := range []bool{false, true} {
+		src := NewCacheWithClock(clock)
+		src.SetPrettyJSON(pretty)
+		src.Add("pokemon/25", body)
+		src.Add("raw", []byte("not json"))
+
+		dir := t.TempDir()
+		if _, err := src.Flush(context.Background(), dir); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, fileName("pokemon/25")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bytes.Contains(data, []byte("\n  ")); got != pretty {
+			t.Errorf("pretty=%v: file indented = %v:\n%s", pretty, got, data)
+		}
+		if !bytes.Contains(data, []byte(`"name"`)) {
+			t.Errorf("pretty=%v: the JSON body is not embedded readably:\n%s", pretty, data)
+		}
+
+		var buf bytes.Buffer
+		if err := src.Export(&buf); err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		sizes = append(sizes, uncompressedSize(t, buf.Bytes()))
+
+		// Whichever way they were written, bodies read back compact.
+		fromDisk := NewCacheWithClock(clock)
+		if _, err := fromDisk.Load(dir); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		fromExport := NewCacheWithClock(clock)
+		if _, err := fromExport.Import(&buf); err != nil {
+			t.Fatalf("Import: %v", err)
+		}
+		for _, dst := range []*Cache{fromDisk, fromExport} {
+			if got, _ := dst.Get("pokemon/25"); !bytes.Equal(got, body) {
+				t.Errorf("pretty=%v: read back %s, want %s", pretty, got, body)
+			}
+			if got, _ := dst.Get("raw"); string(got) != "not json" {
+				t.Errorf("pretty=%v: read back %q for a non-JSON body", pretty, got)
+			}
+		}
+	}
+	if sizes[1] <= sizes[0] {
+		t.Errorf("pretty export is %d bytes, compact %d; want pretty larger", sizes[1], sizes[0])
+	}
+}
+
+func TestEntryReadsBase64Data(t *testing.T) {
+	// Older versions wrote every body as a base64 string.
+	var entry Entry
+	if err := json.Unmarshal([]byte(`{"key":"k","data":"eyJpZCI6MX0=","created_at":"2024-01-01T12:00:00Z"}`), &entry); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if string(entry.Data) != `{"id":1}` {
+		t.Errorf("Data = %q, want the decoded body", entry.Data)
+	}
+}
+
+func uncompressedSize(t *testing.T, gzipped []byte) int {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(gzipped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(data)
+}
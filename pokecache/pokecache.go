@@ -1,46 +1,303 @@
 package pokecache
 
 import (
+	"crypto/sha256"
 	"errors"
-	"time"
+	"log"
+	"sort"
 	"sync"
-	
-
+	"time"
 )
 
+// ttl is how long an entry lives before the reaper drops it, unless
+// SetTTL changes it.
+const ttl = 5 * time.Minute
+
 type cacheEntry struct {
 	createdAt time.Time
 	data      []byte
+	// ttl overrides the default lifetime when non-zero.
+	ttl time.Duration
+	// pinned entries are never reaped (e.g. the embedded dataset).
+	pinned bool
+	// hash identifies data in Cache.bodies when deduplication is on.
+	hash [sha256.Size]byte
+}
+
+// sharedBody is a response body stored once for every key holding it.
+type sharedBody struct {
+	data []byte
+	refs int
 }
 
 type Cache struct {
 	entries map[string]cacheEntry
-	mu 	sync.Mutex
+	// aliases map extra keys to the key an entry is stored under.
+	aliases map[string]string
+	mu      sync.Mutex
+	hits    uint64
+	misses  uint64
+	clock   Clock
+	// logger receives debug output when set.
+	logger *log.Logger
+	// size is the total number of bytes held. When maxBytes is positive,
+	// the oldest entries are evicted to keep size within it.
+	size     int
+	maxBytes int
+	// defaultTTL is the lifetime of entries added without their own.
+	defaultTTL time.Duration
+	// sliding makes Get restart an entry's lifetime, so entries in use are
+	// not reaped.
+	sliding bool
+	// bodies holds each distinct body once, keyed by its hash, when
+	// deduplication is on (non-nil). size then counts each body once.
+	bodies map[[sha256.Size]byte]*sharedBody
+	// pretty indents the JSON written by Flush and Export.
+	pretty bool
+}
+
+// put stores entry under key, keeping size up to date and evicting the
+// oldest entries if the byte budget is exceeded. Callers hold c.mu.
+func (c *Cache) put(key string, entry cacheEntry) {
+	delete(c.aliases, key)
+	c.remove(key)
+	if c.bodies != nil {
+		entry.hash = sha256.Sum256(entry.data)
+		if body, ok := c.bodies[entry.hash]; ok {
+			entry.data = body.data
+			body.refs++
+		} else {
+			c.bodies[entry.hash] = &sharedBody{data: entry.data, refs: 1}
+			c.size += len(entry.data)
+		}
+	} else {
+		c.size += len(entry.data)
+	}
+	c.entries[key] = entry
+	c.evict()
+}
+
+// remove deletes key, keeping size up to date. Callers hold c.mu.
+func (c *Cache) remove(key string) {
+	old, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	if c.bodies == nil {
+		c.size -= len(old.data)
+		return
+	}
+	body := c.bodies[old.hash]
+	body.refs--
+	if body.refs == 0 {
+		delete(c.bodies, old.hash)
+		c.size -= len(old.data)
+	}
+}
+
+// drop removes key along with the aliases pointing at it, for entries that
+// leave the cache for good. Callers hold c.mu.
+func (c *Cache) drop(key string) {
+	c.remove(key)
+	for alias, target := range c.aliases {
+		if target == key {
+			delete(c.aliases, alias)
+		}
+	}
+}
+
+// evict drops the oldest unpinned entries until the cache fits in maxBytes.
+// Callers hold c.mu.
+func (c *Cache) evict() {
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		oldestKey := ""
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if entry.pinned {
+				continue
+			}
+			if oldestKey == "" || entry.createdAt.Before(oldest) {
+				oldestKey, oldest = key, entry.createdAt
+			}
+		}
+		if oldestKey == "" {
+			return
+		}
+		c.logf("cache evict key=%s", oldestKey)
+		c.drop(oldestKey)
+	}
+}
+
+// SetLogger enables debug logging of cache operations to l. A nil l turns
+// logging off.
+func (c *Cache) SetLogger(l *log.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logger = l
+}
+
+// logf writes to the debug logger, if any. Callers hold c.mu.
+func (c *Cache) logf(format string, args ...any) {
+	if c.logger != nil {
+		c.logger.Printf(format, args...)
+	}
 }
 
 func (c *Cache) Add(key string, data []byte) error {
+	return c.AddWithTTL(key, data, 0)
+}
+
+// AddWithTTL is like Add, but the entry expires after entryTTL instead of
+// the default lifetime. A zero entryTTL uses the default.
+func (c *Cache) AddWithTTL(key string, data []byte, entryTTL time.Duration) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.entries[key] = cacheEntry{
-		createdAt: time.Now(),
+	c.put(key, cacheEntry{
+		createdAt: c.clock.Now(),
 		data:      data,
-	}
+		ttl:       entryTTL,
+	})
+	c.logf("cache add key=%s bytes=%d", key, len(data))
 	return nil
 }
 
+// AddAlias makes Get(alias) return the entry stored under key, so the same
+// data fetched through two URLs is only held once.
+func (c *Cache) AddAlias(alias, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.aliases[alias] = key
+	c.logf("cache alias %s -> %s", alias, key)
+}
+
 func (c *Cache) Get(key string) ([]byte, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if target, ok := c.aliases[key]; ok {
+		key = target
+	}
 	entry, ok := c.entries[key]
 	if !ok {
+		c.misses++
+		c.logf("cache miss key=%s", key)
 		return nil, errors.New("key not found")
 	}
+	c.hits++
+	c.logf("cache hit key=%s", key)
+	if c.sliding && !entry.pinned {
+		entry.createdAt = c.clock.Now()
+		c.entries[key] = entry
+	}
 	return entry.data, nil
 }
 
+// Contains reports whether key (or an alias of it) is cached, without
+// counting as a hit or miss.
+func (c *Cache) Contains(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if target, ok := c.aliases[key]; ok {
+		key = target
+	}
+	_, ok := c.entries[key]
+	return ok
+}
+
+// ResetStats zeroes the hit and miss counters.
+func (c *Cache) ResetStats() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits, c.misses = 0, 0
+}
+
+// Stats returns the cumulative number of cache hits and misses.
+func (c *Cache) Stats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+// EntryInfo describes a cache entry without its data.
+type EntryInfo struct {
+	Key       string
+	Size      int
+	CreatedAt time.Time
+	Pinned    bool
+}
+
+// Entries returns metadata for every entry, largest first. Entries of equal
+// size are ordered by key.
+func (c *Cache) Entries() []EntryInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	infos := make([]EntryInfo, 0, len(c.entries))
+	for key, entry := range c.entries {
+		infos = append(infos, EntryInfo{
+			Key:       key,
+			Size:      len(entry.data),
+			CreatedAt: entry.createdAt,
+			Pinned:    entry.pinned,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Size != infos[j].Size {
+			return infos[i].Size > infos[j].Size
+		}
+		return infos[i].Key < infos[j].Key
+	})
+	return infos
+}
+
+// SetTTL changes the lifetime of entries that do not have their own.
+func (c *Cache) SetTTL(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.defaultTTL = d
+}
+
+// SetDedupe makes the cache store identical bodies only once, however many
+// keys hold them, and count them once against its size. The entries already
+// cached are converted right away.
+func (c *Cache) SetDedupe(dedupe bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if dedupe == (c.bodies != nil) {
+		return
+	}
+	entries := c.entries
+	c.entries = make(map[string]cacheEntry, len(entries))
+	c.bodies = nil
+	if dedupe {
+		c.bodies = make(map[[sha256.Size]byte]*sharedBody)
+	}
+	c.size = 0
+	for key, entry := range entries {
+		c.put(key, entry)
+	}
+}
+
+// SetPrettyJSON makes Flush and Export write indented JSON, easier to read
+// when debugging but larger. They write compact JSON by default.
+func (c *Cache) SetPrettyJSON(pretty bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pretty = pretty
+}
+
+// PrettyJSON reports whether Flush and Export write indented JSON.
+func (c *Cache) PrettyJSON() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pretty
+}
+
 func (c *Cache) ReapLoop() {
 	for {
-		time.Sleep(5 * time.Minute)
+		c.mu.Lock()
+		interval := c.defaultTTL
+		c.mu.Unlock()
+		time.Sleep(interval)
 		c.Reap()
 	}
 }
@@ -48,17 +305,81 @@ func (c *Cache) ReapLoop() {
 func (c *Cache) Reap() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	reaped := 0
 	for key, entry := range c.entries {
-		if time.Since(entry.createdAt) > 5*time.Minute {
-			delete(c.entries, key)
+		if entry.pinned {
+			continue
+		}
+		if c.expired(entry.createdAt, entry.ttl) {
+			c.drop(key)
+			reaped++
 		}
 	}
+	c.logf("reaped %d keys", reaped)
+}
+
+// expired reports whether an entry created at createdAt has outlived
+// entryTTL, or the cache's default lifetime when entryTTL is zero. Callers
+// hold c.mu.
+func (c *Cache) expired(createdAt time.Time, entryTTL time.Duration) bool {
+	if entryTTL == 0 {
+		entryTTL = c.defaultTTL
+	}
+	return c.clock.Now().Sub(createdAt) > entryTTL
+}
+
+// Options configures a cache when it is created.
+type Options struct {
+	// Clock is where the cache reads the time; nil means RealClock.
+	Clock Clock
+	// MaxBytes limits the cache to that many bytes of data: the oldest
+	// entries are evicted on every add to stay within it. Pinned entries
+	// count against the budget but are never evicted. Zero means no limit.
+	MaxBytes int
+	// Sliding makes reading an entry restart its lifetime, so only entries
+	// left unused for their whole TTL are reaped. By default entries expire
+	// a fixed time after they were added.
+	Sliding bool
 }
 
 func NewCache() *Cache {
+	return NewCacheWithOptions(Options{})
+}
+
+// NewCacheWithClock returns a cache that reads the time from clock.
+func NewCacheWithClock(clock Clock) *Cache {
+	return NewCacheWithOptions(Options{Clock: clock})
+}
+
+// NewCacheWithOptions returns a cache configured by opts.
+func NewCacheWithOptions(opts Options) *Cache {
+	clock := opts.Clock
+	if clock == nil {
+		clock = RealClock
+	}
 	c := &Cache{
-		entries: make(map[string]cacheEntry),
+		entries:    make(map[string]cacheEntry),
+		aliases:    make(map[string]string),
+		clock:      clock,
+		defaultTTL: ttl,
+		maxBytes:   opts.MaxBytes,
+		sliding:    opts.Sliding,
 	}
 	go c.ReapLoop()
 	return c
 }
+
+// NewCacheFromMap returns a cache seeded with every key/data pair of m, all
+// timestamped now.
+func NewCacheFromMap(m map[string][]byte) *Cache {
+	c := NewCache()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, data := range m {
+		c.put(key, cacheEntry{
+			createdAt: c.clock.Now(),
+			data:      data,
+		})
+	}
+	return c
+}
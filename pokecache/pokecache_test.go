@@ -0,0 +1,408 @@
+package pokecache
+
+import (
+	"bytes"
+	"log"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStatsCountsHitsAndMisses(t *testing.T) {
+	c := NewCacheWithClock(newFakeClock())
+	c.Add("a", []byte("1"))
+	for i := 0; i < 3; i++ {
+		c.Get("a")
+	}
+	c.Get("b")
+	c.Get("c")
+	hits, misses := c.Stats()
+	if hits != 3 || misses != 2 {
+		t.Errorf("Stats() = %d hits, %d misses, want 3 and 2", hits, misses)
+	}
+
+	c.ResetStats()
+	if hits, misses := c.Stats(); hits != 0 || misses != 0 {
+		t.Errorf("after ResetStats, Stats() = %d, %d, want 0, 0", hits, misses)
+	}
+}
+
+func TestStatsConcurrentGets(t *testing.T) {
+	c := NewCacheWithClock(newFakeClock())
+	c.Add("a", []byte("1"))
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Get("a")
+			c.Get("missing")
+		}()
+	}
+	wg.Wait()
+	hits, misses := c.Stats()
+	if hits != 50 || misses != 50 {
+		t.Errorf("Stats() = %d hits, %d misses, want 50 and 50", hits, misses)
+	}
+}
+
+func TestReapExpiresWithFakeClock(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithClock(clock)
+	c.Add("old", []byte("1"))
+	clock.Advance(2 * time.Minute)
+	c.Add("new", []byte("2"))
+
+	clock.Advance(ttl - 2*time.Minute)
+	c.Reap()
+	if !c.Contains("old") {
+		t.Error("reaped an entry exactly at the end of its lifetime")
+	}
+
+	clock.Advance(time.Second)
+	c.Reap()
+	if c.Contains("old") {
+		t.Error("kept an entry past its lifetime")
+	}
+	if !c.Contains("new") {
+		t.Error("reaped an entry that had time left")
+	}
+
+	clock.Advance(2 * time.Minute)
+	c.Reap()
+	if c.Contains("new") {
+		t.Error("kept the second entry past its lifetime")
+	}
+}
+
+func TestSetTTLChangesDefaultLifetime(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithClock(clock)
+	c.SetTTL(time.Minute)
+	c.Add("a", []byte("1"))
+	clock.Advance(time.Minute + time.Second)
+	c.Reap()
+	if c.Contains("a") {
+		t.Error("entry outlived the TTL set with SetTTL")
+	}
+}
+
+func TestNewCacheFromMap(t *testing.T) {
+	seed := map[string][]byte{
+		"https://pokeapi.co/api/v2/pokemon/25":  []byte(`{"name":"pikachu"}`),
+		"https://pokeapi.co/api/v2/pokemon/133": []byte(`{"name":"eevee"}`),
+		"empty":                                 {},
+	}
+	before := time.Now()
+	c := NewCacheFromMap(seed)
+	for key, want := range seed {
+		got, err := c.Get(key)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Get(%q) = %q, %v, want %q", key, got, err, want)
+		}
+	}
+	for _, info := range c.Entries() {
+		if info.CreatedAt.Before(before) || info.CreatedAt.After(time.Now()) {
+			t.Errorf("%s was created at %v, want now", info.Key, info.CreatedAt)
+		}
+	}
+	if n := len(c.Entries()); n != len(seed) {
+		t.Errorf("cache holds %d entries, want %d", n, len(seed))
+	}
+}
+
+func TestPerEntryTTL(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithClock(clock)
+	c.Add("default", []byte("1"))
+	c.AddWithTTL("short", []byte("2"), time.Minute)
+	c.AddWithTTL("long", []byte("3"), time.Hour)
+
+	steps := []struct {
+		advance time.Duration
+		present []string
+		gone    []string
+	}{
+		{time.Minute + time.Second, []string{"default", "long"}, []string{"short"}},
+		{ttl, []string{"long"}, []string{"default"}},
+		{time.Hour, nil, []string{"long"}},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		c.Reap()
+		for _, key := range step.present {
+			if !c.Contains(key) {
+				t.Errorf("step %d: %s expired early", i, key)
+			}
+		}
+		for _, key := range step.gone {
+			if c.Contains(key) {
+				t.Errorf("step %d: %s outlived its TTL", i, key)
+			}
+		}
+	}
+}
+
+func TestLoggerRecordsOperations(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithClock(clock)
+	var buf bytes.Buffer
+	c.SetLogger(log.New(&buf, "", 0))
+
+	c.Add("a", []byte("12345"))
+	c.Get("a")
+	c.Get("b")
+	clock.Advance(ttl + time.Second)
+	c.Reap()
+
+	want := "cache add key=a bytes=5\n" +
+		"cache hit key=a\n" +
+		"cache miss key=b\n" +
+		"reaped 1 keys\n"
+	if got := buf.String(); got != want {
+		t.Errorf("log =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	c.SetLogger(nil)
+	c.Add("c", []byte("1"))
+	if buf.Len() != 0 {
+		t.Errorf("logged %q after SetLogger(nil)", buf.String())
+	}
+}
+
+func TestMaxBytesEvictsOldest(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithOptions(Options{Clock: clock, MaxBytes: 10})
+	for _, key := range []string{"a", "b", "c"} {
+		c.Add(key, []byte("xxxx"))
+		clock.Advance(time.Second)
+	}
+	// 12 bytes do not fit in 10: the oldest entry goes.
+	if c.Contains("a") || !c.Contains("b") || !c.Contains("c") {
+		t.Errorf("entries after the third add: %+v, want b and c", c.Entries())
+	}
+
+	c.Add("big", []byte("xxxxxxxx"))
+	if c.Contains("b") || c.Contains("c") || !c.Contains("big") {
+		t.Errorf("entries after a large add: %+v, want only big", c.Entries())
+	}
+	if size := totalSize(c); size > 10 {
+		t.Errorf("cache holds %d bytes, over the 10 byte budget", size)
+	}
+}
+
+func TestNoMaxBytesByDefault(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithClock(clock)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		c.Add(key, []byte("xxxxxxxxxxxxxxxx"))
+		clock.Advance(time.Second)
+	}
+	if got := len(c.Entries()); got != 4 {
+		t.Errorf("cache holds %d entries without a budget, want 4", got)
+	}
+}
+
+func totalSize(c *Cache) int {
+	size := 0
+	for _, info := range c.Entries() {
+		size += info.Size
+	}
+	return size
+}
+
+func TestEntriesReportsSizesLargestFirst(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithClock(clock)
+	c.Add("small", []byte("12"))
+	clock.Advance(time.Minute)
+	c.Add("large", []byte("1234567890"))
+	c.Add("b-medium", []byte("12345"))
+	c.Add("a-medium", []byte("12345"))
+
+	want := []EntryInfo{
+		{Key: "large", Size: 10, CreatedAt: clock.Now()},
+		{Key: "a-medium", Size: 5, CreatedAt: clock.Now()},
+		{Key: "b-medium", Size: 5, CreatedAt: clock.Now()},
+		{Key: "small", Size: 2, CreatedAt: clock.Now().Add(-time.Minute)},
+	}
+	if got := c.Entries(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSlidingExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		sliding bool
+		want    bool
+	}{
+		{"absolute", false, false},
+		{"sliding", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := NewCacheWithOptions(Options{Clock: clock, Sliding: tt.sliding})
+			c.SetTTL(time.Minute)
+			c.Add("read", []byte("1"))
+			c.AddAlias("alias", "read")
+			c.Add("unread", []byte("2"))
+
+			// Reads every 40s, through the key and its alias, keep a
+			// sliding entry alive well past its first minute.
+			for i := 0; i < 3; i++ {
+				clock.Advance(40 * time.Second)
+				c.Reap()
+				if i%2 == 0 {
+					c.Get("read")
+				} else {
+					c.Get("alias")
+				}
+			}
+			if got := c.Contains("read"); got != tt.want {
+				t.Errorf("entry read every 40s cached after 2m: %v, want %v", got, tt.want)
+			}
+			if c.Contains("unread") {
+				t.Error("an entry nobody read outlived its TTL")
+			}
+		})
+	}
+}
+
+func TestContainsDoesNotSlide(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithOptions(Options{Clock: clock, Sliding: true})
+	c.SetTTL(time.Minute)
+	c.Add("a", []byte("1"))
+	clock.Advance(40 * time.Second)
+	c.Contains("a")
+	clock.Advance(40 * time.Second)
+	c.Reap()
+	if c.Contains("a") {
+		t.Error("Contains restarted the entry's lifetime")
+	}
+}
+
+func TestAlias(t *testing.T) {
+	c := NewCacheWithClock(newFakeClock())
+	c.Add("pokemon/25", []byte("pikachu"))
+	c.AddAlias("pokemon/pikachu", "pokemon/25")
+
+	if data, err := c.Get("pokemon/pikachu"); err != nil || string(data) != "pikachu" {
+		t.Errorf("Get(alias) = %q, %v, want the aliased entry", data, err)
+	}
+	if !c.Contains("pokemon/pikachu") {
+		t.Error("Contains(alias) = false")
+	}
+	if n := len(c.Entries()); n != 1 {
+		t.Errorf("cache holds %d entries, want 1", n)
+	}
+
+	// Replacing the target keeps the alias pointing at it.
+	c.Add("pokemon/25", []byte("pikachu v2"))
+	if data, _ := c.Get("pokemon/pikachu"); string(data) != "pikachu v2" {
+		t.Errorf("Get(alias) after replacing the target = %q", data)
+	}
+
+	// Adding under the alias key stores a separate entry.
+	c.Add("pokemon/pikachu", []byte("own entry"))
+	if data, _ := c.Get("pokemon/pikachu"); string(data) != "own entry" {
+		t.Errorf("Get of a key that was an alias = %q, want its own entry", data)
+	}
+	if data, _ := c.Get("pokemon/25"); string(data) != "pikachu v2" {
+		t.Errorf("the former target now holds %q", data)
+	}
+}
+
+func TestAliasGoesWithItsEntry(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithOptions(Options{Clock: clock, MaxBytes: 10})
+	c.Add("pokemon/25", []byte("xxxx"))
+	c.AddAlias("pokemon/pikachu", "pokemon/25")
+	clock.Advance(time.Second)
+	c.Add("pokemon/1", []byte("xxxx"))
+	c.AddAlias("pokemon/bulbasaur", "pokemon/1")
+	clock.Advance(time.Second)
+
+	// Evicting pokemon/25 takes its alias along.
+	c.Add("pokemon/4", []byte("xxxx"))
+	if c.Contains("pokemon/pikachu") || len(c.aliases) != 1 {
+		t.Errorf("aliases after evicting their entry: %v", c.aliases)
+	}
+
+	// So does reaping pokemon/1.
+	clock.Advance(ttl)
+	c.Reap()
+	if c.Contains("pokemon/bulbasaur") || len(c.aliases) != 0 {
+		t.Errorf("aliases after reaping their entry: %v", c.aliases)
+	}
+}
+
+func TestDedupeSharesBodies(t *testing.T) {
+	c := NewCacheWithClock(newFakeClock())
+	c.SetDedupe(true)
+	body := []byte(`{"id":25,"name":"pikachu"}`)
+	c.Add("pokemon/25", append([]byte(nil), body...))
+	c.Add("pokemon/pikachu", append([]byte(nil), body...))
+	c.Add("pokemon/1", []byte(`{"id":1}`))
+
+	if c.size != len(body)+len(`{"id":1}`) {
+		t.Errorf("size = %d, want each distinct body counted once", c.size)
+	}
+	a, _ := c.Get("pokemon/25")
+	b, _ := c.Get("pokemon/pikachu")
+	if &a[0] != &b[0] {
+		t.Error("identical bodies are stored twice")
+	}
+
+	// The shared body stays until its last key goes.
+	c.Add("pokemon/25", []byte(`{}`))
+	if data, _ := c.Get("pokemon/pikachu"); string(data) != string(body) {
+		t.Errorf("replacing one key changed the other to %q", data)
+	}
+	if c.size != len(body)+len(`{"id":1}`)+len(`{}`) {
+		t.Errorf("size = %d after replacing one of two sharing keys", c.size)
+	}
+	c.Add("pokemon/pikachu", []byte(`{}`))
+	if c.size != len(`{"id":1}`)+len(`{}`) {
+		t.Errorf("size = %d after replacing both sharing keys", c.size)
+	}
+}
+
+func TestSetDedupeConvertsEntries(t *testing.T) {
+	c := NewCacheWithClock(newFakeClock())
+	c.Add("a", []byte("1234567890"))
+	c.Add("b", []byte("1234567890"))
+	if c.size != 20 {
+		t.Fatalf("size = %d without dedupe, want 20", c.size)
+	}
+	c.SetDedupe(true)
+	if c.size != 10 {
+		t.Errorf("size = %d after turning dedupe on, want 10", c.size)
+	}
+	c.SetDedupe(false)
+	if c.size != 20 {
+		t.Errorf("size = %d after turning dedupe off, want 20", c.size)
+	}
+	for _, key := range []string{"a", "b"} {
+		if data, err := c.Get(key); err != nil || string(data) != "1234567890" {
+			t.Errorf("Get(%q) = %q, %v after the conversions", key, data, err)
+		}
+	}
+}
+
+func TestDedupeFitsSharedBodiesInBudget(t *testing.T) {
+	clock := newFakeClock()
+	c := NewCacheWithOptions(Options{Clock: clock, MaxBytes: 10})
+	c.SetDedupe(true)
+	for _, key := range []string{"a", "b", "c"} {
+		c.Add(key, []byte("1234567890"))
+		clock.Advance(time.Second)
+	}
+	if n := len(c.Entries()); n != 3 {
+		t.Errorf("kept %d of 3 keys sharing a body that fits the budget", n)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ablanchetMD/pokedex/pokecache"
+)
+
+// savedEntry is how a caught pokemon is stored in the pokedex file.
+type savedEntry struct {
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// loadReport counts the entries LoadPokedex kept and quarantined.
+type loadReport struct {
+	loaded  int
+	skipped int
+	// corruptPath is where skipped entries were written, if any.
+	corruptPath string
+}
+
+// defaultPokedexPath is where the pokedex was saved before it moved into the
+// profile, see LoadProfile.
+func defaultPokedexPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pokedex.json")
+}
+
+// LoadPokedex reads the pokedex saved at path. Entries that do not parse as a
+// pokemon are skipped rather than failing the whole load, and written to
+// path + ".corrupt" for inspection. A missing file yields an empty pokedex.
+// When cache is non-nil, each loaded pokemon also warms it under its
+// pokeapi URL, so looking it up again needs no request.
+func LoadPokedex(path string, cache *pokecache.Cache) (*pokedex, loadReport, error) {
+	p := NewPokedex()
+	var report loadReport
+	if path == "" {
+		return p, report, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, report, nil
+		}
+		return p, report, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return p, report, err
+	}
+	return loadEntries(raw, cache, path+".corrupt")
+}
+
+// loadEntries builds a pokedex from saved entries, as described for
+// LoadPokedex. Corrupt entries are written to corruptPath.
+func loadEntries(raw map[string]json.RawMessage, cache *pokecache.Cache, corruptPath string) (*pokedex, loadReport, error) {
+	p := NewPokedex()
+	var report loadReport
+	corrupt := make(map[string]json.RawMessage)
+	for key, value := range raw {
+		pokemon, entry, err := parseSavedEntry(value)
+		if err != nil {
+			fmt.Printf("Skipping corrupt pokedex entry %s: %v\n", key, err)
+			corrupt[key] = value
+			continue
+		}
+		p.entries[key] = pokemonEntry{
+			createdAt: entry.CreatedAt,
+			data:      entry.Data,
+			parsed:    &pokemon,
+		}
+		if cache != nil && pokemon.ID > 0 {
+			url := pokemonPrefix + strconv.Itoa(pokemon.ID)
+			cache.AddWithTTL(url, entry.Data, pokemonTTL)
+			cache.AddAlias(pokemonPrefix+pokemon.Name, url)
+		}
+	}
+	report.loaded = len(p.entries)
+	report.skipped = len(corrupt)
+	p.count.Store(int64(report.loaded))
+
+	if len(corrupt) > 0 {
+		report.corruptPath = corruptPath
+		data, err := json.MarshalIndent(corrupt, "", "  ")
+		if err != nil {
+			return p, report, err
+		}
+		if err := os.WriteFile(corruptPath, data, 0644); err != nil {
+			return p, report, err
+		}
+	}
+	return p, report, nil
+}
+
+// parseSavedEntry checks that value is a saved entry holding a pokemon.
+func parseSavedEntry(value json.RawMessage) (Pokemon, savedEntry, error) {
+	var entry savedEntry
+	if err := json.Unmarshal(value, &entry); err != nil {
+		return Pokemon{}, entry, err
+	}
+	if err := validatePokemon(entry.Data); err != nil {
+		return Pokemon{}, entry, err
+	}
+	var pokemon Pokemon
+	if err := json.Unmarshal(entry.Data, &pokemon); err != nil {
+		return Pokemon{}, entry, err
+	}
+	return pokemon, entry, nil
+}
+
+// savedEntries returns every entry in the form it is saved in.
+func (p *pokedex) savedEntries() map[string]savedEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	saved := make(map[string]savedEntry, len(p.entries))
+	for key, entry := range p.entries {
+		saved[key] = savedEntry{Data: entry.data, CreatedAt: entry.createdAt}
+	}
+	return saved
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// writePokedexFile saves entries, keyed by pokedex key, in the pokedex file
+// format.
+func writePokedexFile(t *testing.T, entries map[string]string) string {
+	t.Helper()
+	raw := make(map[string]json.RawMessage, len(entries))
+	for key, entry := range entries {
+		raw[key] = json.RawMessage(entry)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "pokedex.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func savedJSON(body string) string {
+	return `{"data":` + body + `,"created_at":"2024-01-01T12:00:00Z"}`
+}
+
+func TestLoadPokedexSkipsCorruptEntries(t *testing.T) {
+	setupTest(t)
+	path := writePokedexFile(t, map[string]string{
+		"pikachu":   savedJSON(pokemonJSON(25, "pikachu", 112, "electric")),
+		"bulbasaur": savedJSON(pokemonJSON(1, "bulbasaur", 64, "grass")),
+		"missingno": savedJSON(`{"name":"missingno"}`),
+		"glitch":    `"not an entry"`,
+	})
+
+	var dex *pokedex
+	var report loadReport
+	var err error
+	captureOutput(t, func() {
+		dex, report, err = LoadPokedex(path, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.loaded != 2 || report.skipped != 2 {
+		t.Errorf("loaded %d and skipped %d entries, want 2 and 2", report.loaded, report.skipped)
+	}
+	if got, want := dex.Names(), []string{"bulbasaur", "pikachu"}; !reflect.DeepEqual(got, want) || dex.Count() != 2 {
+		t.Errorf("pokedex holds %v (count %d), want %v", got, dex.Count(), want)
+	}
+
+	if report.corruptPath != path+".corrupt" {
+		t.Errorf("corrupt entries written to %q, want %q", report.corruptPath, path+".corrupt")
+	}
+	data, err := os.ReadFile(path + ".corrupt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var corrupt map[string]json.RawMessage
+	if err := json.Unmarshal(data, &corrupt); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := corrupt["missingno"]; !ok || len(corrupt) != 2 {
+		t.Errorf("sidecar holds %s, want missingno and glitch", data)
+	}
+}
+
+func TestLoadPokedexWithoutCorruptEntries(t *testing.T) {
+	setupTest(t)
+	path := writePokedexFile(t, map[string]string{
+		"pikachu": savedJSON(pokemonJSON(25, "pikachu", 112, "electric")),
+	})
+	_, report, err := LoadPokedex(path, nil)
+	if err != nil || report.loaded != 1 || report.skipped != 0 {
+		t.Errorf("LoadPokedex = %+v, %v, want one entry loaded", report, err)
+	}
+	if _, err := os.Stat(path + ".corrupt"); !os.IsNotExist(err) {
+		t.Error("wrote a sidecar with nothing corrupt")
+	}
+}
+
+func TestLoadPokedexWarmsCache(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{})
+	path := writePokedexFile(t, map[string]string{
+		"pikachu": savedJSON(pokemonJSON(25, "pikachu", 112, "electric")),
+	})
+	if _, _, err := LoadPokedex(path, pCache); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, url := range []string{primaryBaseURL + "/pokemon/25", primaryBaseURL + "/pokemon/pikachu"} {
+		body, err := fetchValid(url, validatePokemon)
+		if err != nil || validatePokemon(body) != nil {
+			t.Errorf("fetchValid(%q) = %s, %v, want the loaded pokemon", url, body, err)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("made %d requests for a loaded pokemon", n)
+	}
+	if n := len(pCache.Entries()); n != 1 {
+		t.Errorf("cache holds %d entries, want one shared by id and name", n)
+	}
+}
+
+func TestSavedPokedexIsByteIdentical(t *testing.T) {
+	clock := setupTest(t)
+	save := func(names ...string) []byte {
+		dex := NewPokedex()
+		dex.clock = clock
+		for i, name := range names {
+			if err := dex.Add(name, []byte(pokemonJSON(i+1, name, 64, "normal"))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		path := filepath.Join(t.TempDir(), "profile.json")
+		if err := SaveProfile(path, dex, pTrainer); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	first := save("pikachu", "bulbasaur", "eevee")
+	if again := save("pikachu", "bulbasaur", "eevee"); string(again) != string(first) {
+		t.Errorf("saving the same pokedex twice gave\n%s\nthen\n%s", first, again)
+	}
+	keys := regexp.MustCompile(`"(bulbasaur|eevee|pikachu)":`).FindAllString(string(first), -1)
+	if want := []string{`"bulbasaur":`, `"eevee":`, `"pikachu":`}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("saved keys in order %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// prewarmWorkers bounds how many URLs prewarm fetches at once; requests are
+// further limited by requestSlots and the rate limiter.
+const prewarmWorkers = 4
+
+type prewarmFailure struct {
+	url string
+	err error
+}
+
+// prewarmReport counts what prewarm did with each URL.
+type prewarmReport struct {
+	fetched  int
+	cached   int
+	failures []prewarmFailure
+}
+
+// prewarm fetches every URL of urls that is not cached yet, so it is cached
+// afterwards. Failures are collected in the report in the order of urls.
+func prewarm(urls []string) prewarmReport {
+	var report prewarmReport
+	pending := make([]string, 0, len(urls))
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		key := canonicalizeURL(u)
+		if seen[key] || pCache.Contains(key) {
+			report.cached++
+			continue
+		}
+		seen[key] = true
+		pending = append(pending, u)
+	}
+
+	errs := make([]error, len(pending))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < prewarmWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, errs[i] = fetch(pending[i])
+			}
+		}()
+	}
+	for i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			report.failures = append(report.failures, prewarmFailure{url: pending[i], err: err})
+			continue
+		}
+		report.fetched++
+	}
+	return report
+}
+
+func commandPrewarm(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a file of URLs")
+		return errors.New("no file name provided")
+	}
+	lines, err := readNameList(params[0])
+	if err != nil {
+		fmt.Println("Error reading URL list:", err)
+		return err
+	}
+	urls := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "#") {
+			urls = append(urls, line)
+		}
+	}
+
+	report := prewarm(urls)
+	fmt.Printf("Fetched %d, already cached %d, failed %d\n", report.fetched, report.cached, len(report.failures))
+	for _, failure := range report.failures {
+		fmt.Printf("  - %s: %v\n", failure.url, failure.err)
+	}
+	if len(report.failures) > 0 {
+		return fmt.Errorf("%d of %d URLs failed", len(report.failures), len(urls))
+	}
+	return nil
+}
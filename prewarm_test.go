@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrewarm(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{
+		"/pokemon/pikachu":  pokemonJSON(25, "pikachu", 112, "electric"),
+		"/pokemon/eevee":    pokemonJSON(133, "eevee", 65, "normal"),
+		"/location-area/1/": locationAreaJSON("canalave-city-area", "tentacool"),
+	})
+	pCache.Add(primaryBaseURL+"/type/fire", []byte(typeJSON("fire", nil)))
+
+	report := prewarm([]string{
+		primaryBaseURL + "/pokemon/pikachu",
+		primaryBaseURL + "/pokemon/missingno",
+		primaryBaseURL + "/type/fire",
+		primaryBaseURL + "/pokemon/eevee",
+		primaryBaseURL + "/location-area/1/",
+		primaryBaseURL + "/location-area/1",
+	})
+	if report.fetched != 3 || report.cached != 2 || len(report.failures) != 1 {
+		t.Errorf("prewarm fetched %d, found %d cached and %d failed, want 3, 2 and 1",
+			report.fetched, report.cached, len(report.failures))
+	}
+	if len(report.failures) == 1 && (!strings.HasSuffix(report.failures[0].url, "/missingno") || !isNotFound(report.failures[0].err)) {
+		t.Errorf("failure = %+v, want missingno's 404", report.failures[0])
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("made %d requests, want 4", n)
+	}
+	for _, url := range []string{primaryBaseURL + "/pokemon/pikachu", primaryBaseURL + "/location-area/1"} {
+		if !pCache.Contains(canonicalizeURL(url)) {
+			t.Errorf("%s is not cached after prewarm", url)
+		}
+	}
+
+	// A second run finds everything that worked already cached.
+	report = prewarm([]string{primaryBaseURL + "/pokemon/pikachu", primaryBaseURL + "/pokemon/eevee"})
+	if report.fetched != 0 || report.cached != 2 {
+		t.Errorf("second prewarm fetched %d and found %d cached, want 0 and 2", report.fetched, report.cached)
+	}
+}
+
+func TestCommandPrewarm(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/pikachu": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	list := "# starters\n" + primaryBaseURL + "/pokemon/pikachu\n\n" + primaryBaseURL + "/pokemon/missingno\n"
+	if err := os.WriteFile(path, []byte(list), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out := captureOutput(t, func() {
+		if err := commandPrewarm(path); err == nil || !strings.Contains(err.Error(), "1 of 2 URLs failed") {
+			t.Errorf("prewarm = %v, want one of two URLs failed", err)
+		}
+	})
+	if !strings.Contains(out, "Fetched 1, already cached 0, failed 1") || !strings.Contains(out, "/pokemon/missingno: ") {
+		t.Errorf("prewarm printed:\n%s", out)
+	}
+
+	captureOutput(t, func() {
+		if err := commandPrewarm(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+			t.Error("prewarm of a missing file succeeded")
+		}
+	})
+}
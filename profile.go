@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ablanchetMD/pokedex/pokecache"
+)
+
+// profileVersion is the schema version written to the profile. Version 1
+// was the separate ~/.pokedex.json and ~/.pokedex_trainer.json files, which
+// LoadProfile migrates.
+const profileVersion = 2
+
+// profileFile is the saved form of the whole trainer state.
+type profileFile struct {
+	Version int                        `json:"version"`
+	Trainer json.RawMessage            `json:"trainer"`
+	Pokedex map[string]json.RawMessage `json:"pokedex"`
+}
+
+// profilePath is where the profile is saved after every change. Saving is
+// disabled when it is empty.
+var profilePath string
+
+func defaultProfilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pokedex_profile.json")
+}
+
+// openProfile loads the profile saved at path into pDex and pTrainer and
+// makes path where they are saved from now on. A non-empty trainerName
+// replaces the saved name.
+func openProfile(path, trainerName string) {
+	profilePath = path
+	var report loadReport
+	var err error
+	pDex, pTrainer, report, err = LoadProfile(path, pCache)
+	if err != nil {
+		fmt.Println("Error loading profile:", err)
+	}
+	if trainerName != "" {
+		pTrainer.Name = trainerName
+	}
+	if report.skipped > 0 {
+		fmt.Printf("Loaded %d pokemon, skipped %d corrupt entries (see %s)\n", report.loaded, report.skipped, report.corruptPath)
+	}
+}
+
+// profileSaved reports whether a profile is saved at profilePath, which
+// tells a returning trainer from a new one.
+func profileSaved() bool {
+	if profilePath == "" {
+		return false
+	}
+	_, err := os.Stat(profilePath)
+	return err == nil
+}
+
+// saveProfile writes pDex and pTrainer to profilePath.
+func saveProfile() error {
+	return SaveProfile(profilePath, pDex, pTrainer)
+}
+
+// SaveProfile writes dex and t to path as a single versioned file. The file
+// is replaced atomically, so a crash never leaves half a profile behind.
+func SaveProfile(path string, dex *pokedex, t *trainer) error {
+	if path == "" {
+		return nil
+	}
+	trainerData, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	saved := profileFile{
+		Version: profileVersion,
+		Trainer: trainerData,
+		Pokedex: make(map[string]json.RawMessage),
+	}
+	for key, entry := range dex.savedEntries() {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		saved.Pokedex[key] = data
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".profile-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadProfile reads the profile saved at path; its pokedex is loaded like
+// LoadPokedex does, with corrupt entries written to path + ".corrupt". When
+// there is no profile yet, the version 1 files are migrated into one. The
+// returned pokedex and trainer are usable even when err is set.
+func LoadProfile(path string, cache *pokecache.Cache) (*pokedex, *trainer, loadReport, error) {
+	var report loadReport
+	if path == "" {
+		return NewPokedex(), newTrainer(), report, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return migrateProfile(path, cache)
+	}
+	if err != nil {
+		return NewPokedex(), newTrainer(), report, err
+	}
+	return parseProfile(data, path, cache)
+}
+
+// parseProfile decodes a profile read from path, see LoadProfile.
+func parseProfile(data []byte, path string, cache *pokecache.Cache) (*pokedex, *trainer, loadReport, error) {
+	var report loadReport
+	var saved profileFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return NewPokedex(), newTrainer(), report, err
+	}
+	if saved.Version != profileVersion {
+		return NewPokedex(), newTrainer(), report, fmt.Errorf("unsupported profile version %d", saved.Version)
+	}
+	t := newTrainer()
+	if len(saved.Trainer) > 0 {
+		if err := json.Unmarshal(saved.Trainer, t); err != nil {
+			return NewPokedex(), newTrainer(), report, err
+		}
+	}
+	dex, report, err := loadEntries(saved.Pokedex, cache, path+".corrupt")
+	return dex, t, report, err
+}
+
+// migrateProfile builds a profile from the version 1 files and saves it to
+// path. The old files are left in place.
+func migrateProfile(path string, cache *pokecache.Cache) (*pokedex, *trainer, loadReport, error) {
+	dex, report, err := LoadPokedex(defaultPokedexPath(), cache)
+	if err != nil {
+		return dex, newTrainer(), report, err
+	}
+	t, err := loadTrainer(defaultTrainerPath())
+	if err != nil {
+		return dex, newTrainer(), report, err
+	}
+	if dex.Count() == 0 && t.XP == 0 {
+		return dex, t, report, nil
+	}
+	fmt.Printf("Migrating your pokedex and trainer to %s\n", path)
+	return dex, t, report, SaveProfile(path, dex, t)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	setupTest(t)
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	catchPokemon(t, pokemonJSON(1, "bulbasaur", 64, "grass"))
+	pTrainer.XP = 340
+	pTrainer.Streak = 2
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := SaveProfile(path, pDex, pTrainer); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+
+	dex, tr, report, err := LoadProfile(path, pCache)
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if got, want := dex.Names(), []string{"bulbasaur", "pikachu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded pokedex %v, want %v", got, want)
+	}
+	if tr.XP != 340 || tr.Streak != 2 {
+		t.Errorf("loaded trainer with %d XP and a streak of %d, want 340 and 2", tr.XP, tr.Streak)
+	}
+	if report.skipped != 0 {
+		t.Errorf("skipped %d entries of a clean profile", report.skipped)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".profile-*")); len(matches) != 0 {
+		t.Errorf("saving left temporary files behind: %v", matches)
+	}
+}
+
+func TestLoadProfileUnsupportedVersion(t *testing.T) {
+	setupTest(t)
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := os.WriteFile(path, []byte(`{"version":3,"pokedex":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dex, tr, _, err := LoadProfile(path, pCache)
+	if err == nil || !strings.Contains(err.Error(), "version 3") {
+		t.Errorf("LoadProfile error = %v, want an unsupported version", err)
+	}
+	if dex == nil || tr == nil {
+		t.Error("LoadProfile returned an unusable pokedex or trainer")
+	}
+}
+
+func TestLoadProfileMigratesVersion1(t *testing.T) {
+	setupTest(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	oldDex := writePokedexFile(t, map[string]string{
+		"pikachu": savedJSON(pokemonJSON(25, "pikachu", 112, "electric")),
+	})
+	if err := os.Rename(oldDex, filepath.Join(home, ".pokedex.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".pokedex_trainer.json"), []byte(`{"xp":120}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(home, ".pokedex_profile.json")
+	var dex *pokedex
+	var tr *trainer
+	out := captureOutput(t, func() {
+		var err error
+		dex, tr, _, err = LoadProfile(path, pCache)
+		if err != nil {
+			t.Errorf("LoadProfile: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Migrating") {
+		t.Errorf("migration printed %q", out)
+	}
+	if dex.Count() != 1 || tr.XP != 120 {
+		t.Errorf("migrated %d pokemon and %d XP, want 1 and 120", dex.Count(), tr.XP)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".pokedex.json")); err != nil {
+		t.Errorf("the old pokedex file is gone: %v", err)
+	}
+
+	// The migrated profile now loads by itself.
+	dex, tr, _, err := LoadProfile(path, pCache)
+	if err != nil || dex.Count() != 1 || tr.XP != 120 {
+		t.Errorf("reloading the migrated profile = %d pokemon, %d XP, %v", dex.Count(), tr.XP, err)
+	}
+}
+
+func TestLoadProfileWithoutAnySave(t *testing.T) {
+	setupTest(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".pokedex_profile.json")
+	dex, tr, _, err := LoadProfile(path, pCache)
+	if err != nil || dex.Count() != 0 || tr.XP != 0 {
+		t.Errorf("LoadProfile = %d pokemon, %d XP, %v, want a fresh start", dex.Count(), tr.XP, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("a profile was written with nothing to migrate: %v", err)
+	}
+}
+
+func TestOpenProfile(t *testing.T) {
+	setupTest(t)
+	path := filepath.Join(t.TempDir(), "profile.json")
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	pTrainer.Name = "Red"
+	if err := SaveProfile(path, pDex, pTrainer); err != nil {
+		t.Fatal(err)
+	}
+
+	setupTest(t)
+	openProfile(path, "")
+	if profilePath != path || pDex.Count() != 1 || pTrainer.Name != "Red" {
+		t.Errorf("openProfile set profilePath %q, %d pokemon, trainer %q", profilePath, pDex.Count(), pTrainer.Name)
+	}
+	openProfile(path, "Ash")
+	if pTrainer.Name != "Ash" {
+		t.Errorf("trainer name = %q, want the override", pTrainer.Name)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// teamSize is how many pokemon randomteam suggests.
+const teamSize = 6
+
+// teamWorkers bounds how many pokemon randomteam fetches at once; requests
+// are further limited by requestSlots.
+const teamWorkers = 4
+
+// pickTeam returns up to n distinct names from names in a random order drawn
+// from r. Asking for more than n lets the caller replace pokemon that fail
+// to fetch.
+func pickTeam(names []string, r *rand.Rand, n int) []string {
+	picked := make([]string, 0, n)
+	seen := make(map[string]bool)
+	for _, i := range r.Perm(len(names)) {
+		if len(picked) == n {
+			break
+		}
+		if seen[names[i]] {
+			continue
+		}
+		seen[names[i]] = true
+		picked = append(picked, names[i])
+	}
+	return picked
+}
+
+// fetchTeam fetches every named pokemon concurrently and returns them in the
+// order of names. Pokemon pokeapi does not know are skipped.
+func fetchTeam(names []string) ([]Pokemon, error) {
+	team := make([]*Pokemon, len(names))
+	errs := make([]error, len(names))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < teamWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				body, err := fetchValid(pokemonPrefix+names[i], validatePokemon)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				var pokemon Pokemon
+				if err := json.Unmarshal(body, &pokemon); err != nil {
+					errs[i] = err
+					continue
+				}
+				team[i] = &pokemon
+			}
+		}()
+	}
+	for i := range names {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	pokemon := make([]Pokemon, 0, len(names))
+	for i := range names {
+		if errs[i] != nil {
+			if isNotFound(errs[i]) {
+				continue
+			}
+			return nil, errs[i]
+		}
+		pokemon = append(pokemon, *team[i])
+	}
+	return pokemon, nil
+}
+
+func commandRandomTeam(params ...string) error {
+	names, err := pokemonNames()
+	if err != nil {
+		return err
+	}
+	candidates := pickTeam(names, rng, len(names))
+	team := make([]Pokemon, 0, teamSize)
+	for len(team) < teamSize && len(candidates) > 0 {
+		batch := candidates[:min(teamSize-len(team), len(candidates))]
+		candidates = candidates[len(batch):]
+		fetched, err := fetchTeam(batch)
+		if err != nil {
+			fmt.Println("Error fetching team:", err)
+			return err
+		}
+		team = append(team, fetched...)
+	}
+
+	fmt.Println("Your random team:")
+	for _, pokemon := range team {
+		types := make([]string, 0, len(pokemon.Types))
+		for _, t := range pokemon.Types {
+			types = append(types, t.Type.Name)
+		}
+		fmt.Printf("  - %s (%s)\n", pokemon.Name, strings.Join(types, "/"))
+	}
+	return nil
+}
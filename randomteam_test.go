@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPickTeam(t *testing.T) {
+	names := []string{"bulbasaur", "charmander", "squirtle", "pikachu", "pikachu", "eevee", "snorlax", "mew", "ditto"}
+	first := pickTeam(names, rand.New(rand.NewSource(1)), teamSize)
+	second := pickTeam(names, rand.New(rand.NewSource(1)), teamSize)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed picked %v, then %v", first, second)
+	}
+	if len(first) != teamSize {
+		t.Fatalf("picked %d pokemon, want %d", len(first), teamSize)
+	}
+	seen := make(map[string]bool)
+	for _, name := range first {
+		if seen[name] {
+			t.Errorf("picked %s twice in %v", name, first)
+		}
+		seen[name] = true
+	}
+
+	if all := pickTeam(names, rand.New(rand.NewSource(1)), 100); len(all) != 8 {
+		t.Errorf("asking for more than there are picked %v, want the 8 distinct names", all)
+	}
+}
+
+func TestRandomTeamSkipsUnknownPokemon(t *testing.T) {
+	setupTest(t)
+	names := []string{"bulbasaur", "charmander", "squirtle", "pikachu", "eevee", "snorlax", "mew", "ditto"}
+	bodies := map[string]string{"/pokemon?limit=100000": listJSON(len(names)+1, "", append(names, "missingno")...)}
+	for i, name := range names {
+		bodies["/pokemon/"+name] = pokemonJSON(i+1, name, 50, "normal")
+	}
+	stubAPI(t, bodies)
+
+	out := captureOutput(t, func() {
+		if err := commandRandomTeam(); err != nil {
+			t.Errorf("randomteam: %v", err)
+		}
+	})
+
+	// The same seed orders the candidates the same way; missingno is not
+	// served, so it is replaced by the next candidate.
+	want := make([]string, 0, teamSize)
+	for _, name := range pickTeam(append(names, "missingno"), rand.New(rand.NewSource(1)), len(names)+1) {
+		if name != "missingno" && len(want) < teamSize {
+			want = append(want, name)
+		}
+	}
+	got := make([]string, 0, teamSize)
+	for _, line := range strings.Split(out, "\n") {
+		if name, ok := strings.CutPrefix(line, "  - "); ok {
+			got = append(got, strings.TrimSuffix(name, " (normal)"))
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("team = %v, want %v", got, want)
+	}
+}
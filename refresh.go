@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+func commandRefresh(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a Pokemon name")
+		return errors.New("no Pokemon name provided")
+	}
+	old, err := pDex.Get(params[0])
+	if err != nil {
+		fmt.Println("You have not caught that pokemon yet (or there was an error):", params[0])
+		return err
+	}
+
+	url := pokemonPrefix + params[0]
+	body, err := fetchRemote(url, validatePokemon)
+	if err != nil {
+		return err
+	}
+	err = cacheBody(canonicalizeURL(url), body)
+	if err != nil {
+		fmt.Println("Error adding to cache:", err)
+		return err
+	}
+
+	changes, err := diffJSON(old, body)
+	if err != nil {
+		fmt.Println("Error comparing data:", err)
+		return err
+	}
+	pDex.Add(params[0], body)
+	if err := saveProfile(); err != nil {
+		fmt.Println("Error saving pokedex:", err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No changes for", params[0])
+		return nil
+	}
+	fmt.Printf("Refreshed %s:\n", params[0])
+	for _, change := range changes {
+		fmt.Println("  ", change)
+	}
+	return nil
+}
+
+// diffJSON compares the top-level fields of two JSON objects and describes
+// each one that differs, e.g. "base_experience: 64 -> 66". Objects and
+// arrays are only reported as changed.
+func diffJSON(oldData, newData []byte) ([]string, error) {
+	var oldFields, newFields map[string]json.RawMessage
+	if err := json.Unmarshal(oldData, &oldFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(newData, &newFields); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(newFields))
+	for k := range oldFields {
+		keys = append(keys, k)
+	}
+	for k := range newFields {
+		if _, ok := oldFields[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]string, 0)
+	for _, k := range keys {
+		oldValue, newValue := compactJSON(oldFields[k]), compactJSON(newFields[k])
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+		if isComposite(oldValue) || isComposite(newValue) {
+			changes = append(changes, k+": changed")
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", k, displayJSON(oldValue), displayJSON(newValue)))
+	}
+	return changes, nil
+}
+
+func compactJSON(raw json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+func isComposite(raw []byte) bool {
+	return len(raw) > 0 && (raw[0] == '{' || raw[0] == '[')
+}
+
+func displayJSON(raw []byte) string {
+	if len(raw) == 0 {
+		return "(none)"
+	}
+	return string(raw)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []string
+	}{
+		{"unchanged", `{"id":1,"name":"bulbasaur"}`, `{"name": "bulbasaur", "id": 1}`, []string{}},
+		{"one field", `{"base_experience":64,"id":1}`, `{"base_experience":66,"id":1}`, []string{"base_experience: 64 -> 66"}},
+		{"string", `{"name":"a"}`, `{"name":"b"}`, []string{`name: "a" -> "b"`}},
+		{"added", `{"id":1}`, `{"id":1,"order":2}`, []string{"order: (none) -> 2"}},
+		{"removed", `{"id":1,"order":2}`, `{"id":1}`, []string{"order: 2 -> (none)"}},
+		{"composite", `{"stats":[1]}`, `{"stats":[2]}`, []string{"stats: changed"}},
+		{"whitespace only", `{"stats":[1, 2]}`, `{"stats":[1,2]}`, []string{}},
+	}
+	for _, tt := range tests {
+		got, err := diffJSON([]byte(tt.old), []byte(tt.new))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: diffJSON = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffJSONRejectsNonObjects(t *testing.T) {
+	if _, err := diffJSON([]byte(`[]`), []byte(`{}`)); err == nil {
+		t.Error("diffJSON accepted an array")
+	}
+}
+
+func TestRefreshPrintsChanges(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/bulbasaur": pokemonJSON(1, "bulbasaur", 66, "grass"),
+	})
+	catchPokemon(t, pokemonJSON(1, "bulbasaur", 64, "grass"))
+
+	out := captureOutput(t, func() {
+		if err := commandRefresh("bulbasaur"); err != nil {
+			t.Errorf("refresh: %v", err)
+		}
+	})
+	if !strings.Contains(out, "base_experience: 64 -> 66") {
+		t.Errorf("output lacks the change:\n%s", out)
+	}
+	if pokemon, err := pDex.GetPokemon("bulbasaur"); err != nil || pokemon.BaseExperience != 66 {
+		t.Errorf("pokedex holds %+v, %v after refresh, want base experience 66", pokemon, err)
+	}
+}
+
+func TestRefreshKeepsNameAlias(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/bulbasaur": pokemonJSON(1, "bulbasaur", 66, "grass"),
+	})
+	catchPokemon(t, pokemonJSON(1, "bulbasaur", 64, "grass"))
+	pCache.Add(primaryBaseURL+"/pokemon/1", []byte(pokemonJSON(1, "bulbasaur", 64, "grass")))
+	pCache.AddAlias(primaryBaseURL+"/pokemon/bulbasaur", primaryBaseURL+"/pokemon/1")
+
+	captureOutput(t, func() {
+		if err := commandRefresh("bulbasaur"); err != nil {
+			t.Errorf("refresh: %v", err)
+		}
+	})
+	if n := len(pCache.Entries()); n != 1 {
+		t.Errorf("cache holds %d entries after refresh, want 1", n)
+	}
+	for _, url := range []string{primaryBaseURL + "/pokemon/bulbasaur", primaryBaseURL + "/pokemon/1"} {
+		body, err := pCache.Get(url)
+		if err != nil || !strings.Contains(string(body), `"base_experience":66`) {
+			t.Errorf("cache has %s, %v for %s after refresh, want the refreshed pokemon", body, err, url)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxReplayDepth stops replay files from replaying each other forever.
+const maxReplayDepth = 5
+
+var replayDepth int
+
+func commandReplay(params ...string) error {
+	params, flags := parseFlags(params)
+	if len(params) < 1 {
+		fmt.Println("Please provide a file name")
+		return errors.New("no file name provided")
+	}
+	if replayDepth >= maxReplayDepth {
+		fmt.Println("Replays are nested too deeply")
+		return errors.New("replay nested too deeply")
+	}
+	file, err := os.Open(params[0])
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return err
+	}
+	defer file.Close()
+
+	replayDepth++
+	defer func() { replayDepth-- }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fmt.Println("---", line)
+		err := dispatch(line)
+		if err != nil && !flags["continue"] {
+			return fmt.Errorf("replay stopped at %q: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeScript(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.txt")
+	if err := os.WriteFile(path, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const replayScript = "# set things up\ndicesides 12\n\n  bogus  \nmovelimit 3\n"
+
+func TestReplayRunsEachCommand(t *testing.T) {
+	setupTest(t)
+	path := writeScript(t, "# set things up\ndicesides 12\n\nmovelimit 3\n")
+	out := captureOutput(t, func() {
+		if err := commandReplay(path); err != nil {
+			t.Errorf("replay: %v", err)
+		}
+	})
+	if diceSides != 12 || moveLimit != 3 {
+		t.Errorf("diceSides = %d, moveLimit = %d, want 12 and 3", diceSides, moveLimit)
+	}
+	if got := strings.Count(out, "--- "); got != 2 || !strings.Contains(out, "--- dicesides 12\n") {
+		t.Errorf("printed %d separators, want one per command:\n%s", got, out)
+	}
+	if _, commands, _ := currentSession.Counts(); commands != 2 {
+		t.Errorf("session counted %d commands, want 2", commands)
+	}
+}
+
+func TestReplayStopsAtFirstError(t *testing.T) {
+	setupTest(t)
+	path := writeScript(t, replayScript)
+	captureOutput(t, func() {
+		if err := commandReplay(path); !errors.Is(err, errUnknownCommand) {
+			t.Errorf("replay = %v, want it to stop at the unknown command", err)
+		}
+	})
+	if diceSides != 12 || moveLimit != 10 {
+		t.Errorf("diceSides = %d, moveLimit = %d, want 12 and the default 10", diceSides, moveLimit)
+	}
+}
+
+func TestReplayContinue(t *testing.T) {
+	setupTest(t)
+	path := writeScript(t, replayScript)
+	captureOutput(t, func() {
+		if err := commandReplay(path, "--continue"); err != nil {
+			t.Errorf("replay --continue: %v", err)
+		}
+	})
+	if diceSides != 12 || moveLimit != 3 {
+		t.Errorf("diceSides = %d, moveLimit = %d, want 12 and 3", diceSides, moveLimit)
+	}
+}
+
+func TestReplayNestingIsBounded(t *testing.T) {
+	setupTest(t)
+	path := filepath.Join(t.TempDir(), "loop.txt")
+	if err := os.WriteFile(path, []byte("replay "+path+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out := captureOutput(t, func() {
+		if err := commandReplay(path); err == nil {
+			t.Error("a script replaying itself succeeded")
+		}
+	})
+	if !strings.Contains(out, "Replays are nested too deeply") || replayDepth != 0 {
+		t.Errorf("depth %d after replay, output:\n%s", replayDepth, out)
+	}
+}
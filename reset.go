@@ -0,0 +1,29 @@
+package main
+
+import "fmt"
+
+// resetCounters clears everything tracked about play so far: the session
+// counters and duration, cache hit/miss stats, the catch log and trainer
+// XP and streak. The pokedex and the cached data are left alone.
+func resetCounters() error {
+	currentSession.Reset(pDex.clock.Now())
+	pCache.ResetStats()
+	pCatchLog = newCatchLog(catchLogSize)
+	pTrainer.XP = 0
+	pTrainer.Streak = 0
+	return pTrainer.Save()
+}
+
+func commandReset(params ...string) error {
+	if !confirm("Reset session counters, cache stats, catch log and trainer XP?") {
+		fmt.Println("Nothing was reset.")
+		return nil
+	}
+	err := resetCounters()
+	if err != nil {
+		fmt.Println("Error saving trainer:", err)
+		return err
+	}
+	fmt.Println("Counters reset. Your pokedex was kept.")
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// playSome leaves something in every counter reset clears.
+func playSome(t *testing.T) {
+	t.Helper()
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	currentSession.CountCommand()
+	currentSession.CountCatch()
+	pCache.Add("a", []byte("{}"))
+	pCache.Get("a")
+	pCache.Get("b")
+	pCatchLog.Record(catchAttempt{name: "pikachu", caught: true})
+	pTrainer.XP = 500
+	pTrainer.Streak = 3
+}
+
+func TestResetCounters(t *testing.T) {
+	clock := setupTest(t)
+	playSome(t)
+	clock.Advance(time.Hour)
+
+	if err := resetCounters(); err != nil {
+		t.Fatal(err)
+	}
+	if start, commands, caught := currentSession.Counts(); !start.Equal(clock.Now()) || commands != 0 || caught != 0 {
+		t.Errorf("session = %v, %d commands, %d caught, want a fresh session", start, commands, caught)
+	}
+	if hits, misses := pCache.Stats(); hits != 0 || misses != 0 {
+		t.Errorf("cache stats = %d, %d, want zero", hits, misses)
+	}
+	if attempts := pCatchLog.Attempts(); len(attempts) != 0 {
+		t.Errorf("catch log holds %v", attempts)
+	}
+	if pTrainer.XP != 0 || pTrainer.Streak != 0 {
+		t.Errorf("trainer has %d XP and a streak of %d, want zero", pTrainer.XP, pTrainer.Streak)
+	}
+
+	if _, err := pDex.Get("pikachu"); err != nil {
+		t.Error("reset emptied the pokedex")
+	}
+	if !pCache.Contains("a") {
+		t.Error("reset emptied the cache")
+	}
+}
+
+func TestResetNeedsConfirmation(t *testing.T) {
+	setupTest(t)
+	playSome(t)
+	// Without a person at a terminal there is nobody to confirm.
+	out := captureOutput(t, func() {
+		if err := commandReset(); err != nil {
+			t.Errorf("reset: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Nothing was reset.") {
+		t.Errorf("reset printed:\n%s", out)
+	}
+	if pTrainer.XP != 500 {
+		t.Errorf("XP = %d, want it kept", pTrainer.XP)
+	}
+}
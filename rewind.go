@@ -0,0 +1,85 @@
+package main
+
+import "fmt"
+
+// catchUndo is what rewind needs to undo a catch: the key it was stored
+// under and, when the pokemon had been caught before, the entry it replaced.
+type catchUndo struct {
+	key      string
+	previous pokemonEntry
+	existed  bool
+}
+
+// lastCatch undoes the most recent catch, or is nil once it has been
+// rewound. Only that one catch can be undone.
+var lastCatch *catchUndo
+
+// Remove deletes the entry stored under key and reports whether it existed.
+func (p *pokedex) Remove(key string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.entries[key]; !ok {
+		return false
+	}
+	delete(p.entries, key)
+	p.count.Add(-1)
+	return true
+}
+
+// entry returns the entry stored under key, if any.
+func (p *pokedex) entry(key string) (pokemonEntry, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.entries[key]
+	return entry, ok
+}
+
+// restore puts entry back under key, as it was before being replaced.
+func (p *pokedex) restore(key string, entry pokemonEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.entries[key]; !exists {
+		p.count.Add(1)
+	}
+	p.entries[key] = entry
+}
+
+// recordCatch adds data to dex under key and remembers how to undo it.
+func recordCatch(dex *pokedex, key string, data []byte) {
+	previous, existed := dex.entry(key)
+	dex.Add(key, data)
+	lastCatch = &catchUndo{key: key, previous: previous, existed: existed}
+}
+
+// rewindCatch undoes the last catch recorded in dex: a pokemon caught for the
+// first time is removed, one caught again gets its previous entry back. It
+// returns false when there is nothing left to rewind.
+func rewindCatch(dex *pokedex) (catchUndo, bool) {
+	undo := lastCatch
+	lastCatch = nil
+	if undo == nil {
+		return catchUndo{}, false
+	}
+	if undo.existed {
+		dex.restore(undo.key, undo.previous)
+		return *undo, true
+	}
+	if !dex.Remove(undo.key) {
+		return catchUndo{}, false
+	}
+	return *undo, true
+}
+
+func commandRewind(params ...string) error {
+	undo, ok := rewindCatch(pDex)
+	if !ok {
+		fmt.Println("Nothing to rewind.")
+		return nil
+	}
+	if undo.existed {
+		fmt.Println("Rewound the last catch, your previous", undo.key, "is back.")
+	} else {
+		fmt.Println("Released", undo.key, "back into the wild.")
+	}
+	return saveProfile()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRewindFirstCatch(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/pikachu": pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	captureOutput(t, func() {
+		if err := commandCatch("pikachu", "--ball", "master"); err != nil {
+			t.Errorf("catch: %v", err)
+		}
+	})
+	if pDex.Count() != 1 {
+		t.Fatalf("pokedex holds %d pokemon after the catch, want 1", pDex.Count())
+	}
+
+	out := captureOutput(t, func() {
+		if err := commandRewind(); err != nil {
+			t.Errorf("rewind: %v", err)
+		}
+	})
+	if out != "Released pikachu back into the wild.\n" {
+		t.Errorf("rewind printed %q", out)
+	}
+	if pDex.Count() != 0 || len(pDex.Names()) != 0 {
+		t.Errorf("pokedex holds %v after rewinding", pDex.Names())
+	}
+
+	out = captureOutput(t, func() {
+		if err := commandRewind(); err != nil {
+			t.Errorf("second rewind: %v", err)
+		}
+	})
+	if out != "Nothing to rewind.\n" {
+		t.Errorf("second rewind printed %q", out)
+	}
+}
+
+func TestRewindRepeatCatchRestoresEntry(t *testing.T) {
+	clock := setupTest(t)
+	catchPokemon(t, pokemonJSON(25, "pikachu", 100, "electric"))
+	caughtAt := clock.Now()
+	clock.Advance(time.Hour)
+	recordCatch(pDex, "pikachu", []byte(pokemonJSON(25, "pikachu", 112, "electric")))
+
+	out := captureOutput(t, func() {
+		if err := commandRewind(); err != nil {
+			t.Errorf("rewind: %v", err)
+		}
+	})
+	if !strings.Contains(out, "your previous pikachu is back") {
+		t.Errorf("rewind printed %q", out)
+	}
+	pokemon, err := pDex.GetPokemon("pikachu")
+	if err != nil || pokemon.BaseExperience != 100 {
+		t.Errorf("pokedex holds %+v, %v, want the first pikachu back", pokemon, err)
+	}
+	if entry, _ := pDex.entry("pikachu"); !entry.createdAt.Equal(caughtAt) {
+		t.Errorf("restored entry was caught at %v, want %v", entry.createdAt, caughtAt)
+	}
+	if pDex.Count() != 1 {
+		t.Errorf("Count() = %d after rewinding a repeat catch, want 1", pDex.Count())
+	}
+}
+
+func TestRewindOnlyTheLastCatch(t *testing.T) {
+	setupTest(t)
+	recordCatch(pDex, "bulbasaur", []byte(pokemonJSON(1, "bulbasaur", 64, "grass")))
+	recordCatch(pDex, "pikachu", []byte(pokemonJSON(25, "pikachu", 112, "electric")))
+	captureOutput(t, func() {
+		commandRewind()
+		commandRewind()
+	})
+	if names := pDex.Names(); len(names) != 1 || names[0] != "bulbasaur" {
+		t.Errorf("pokedex holds %v, want only the earlier catch left", names)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// defaultScrollbackSize is how many location pages recent keeps until the
+// scrollback command changes it.
+const defaultScrollbackSize = 5
+
+type scrollbackPage struct {
+	number int
+	names  []string
+}
+
+// scrollback retains the most recently displayed pages, oldest first.
+type scrollback struct {
+	pages []scrollbackPage
+	size  int
+}
+
+func newScrollback(size int) *scrollback {
+	return &scrollback{size: size}
+}
+
+// Record appends a page, dropping the oldest once size is exceeded.
+func (s *scrollback) Record(number int, names []string) {
+	s.pages = append(s.pages, scrollbackPage{number: number, names: names})
+	s.trim()
+}
+
+// Resize changes how many pages are kept, dropping the oldest ones that no
+// longer fit.
+func (s *scrollback) Resize(size int) {
+	s.size = size
+	s.trim()
+}
+
+func (s *scrollback) trim() {
+	if len(s.pages) > s.size {
+		s.pages = s.pages[len(s.pages)-s.size:]
+	}
+}
+
+// pageNumber derives the 1-based page number of a list URL from its offset
+// and limit query parameters.
+func pageNumber(rawURL string) int {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 1
+	}
+	offset, _ := strconv.Atoi(u.Query().Get("offset"))
+	limit, err := strconv.Atoi(u.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	return offset/limit + 1
+}
+
+var pScrollback = newScrollback(defaultScrollbackSize)
+
+func commandRecent(params ...string) error {
+	limit, _, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	if len(pScrollback.pages) == 0 {
+		fmt.Println("No location pages viewed yet. Try 'map'.")
+		return nil
+	}
+	for _, page := range pScrollback.pages {
+		fmt.Printf("Page %d:\n", page.number)
+		printList(page.names, limit)
+	}
+	return nil
+}
+
+func commandScrollback(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Scrollback pages:", pScrollback.size)
+		return nil
+	}
+	n, err := strconv.Atoi(params[0])
+	if err != nil || n < 1 {
+		fmt.Println("Please provide a positive number")
+		return errors.New("invalid scrollback size")
+	}
+	pScrollback.Resize(n)
+	fmt.Println("Scrollback set to", n, "pages")
+	return nil
+}
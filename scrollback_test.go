@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecentShowsRetainedPages(t *testing.T) {
+	setupTest(t)
+	stubLocationPages(t)
+	pScrollback.Resize(2)
+	api := newPaginator(primaryBaseURL + "/location-area")
+	api.Scrollback = pScrollback
+
+	captureOutput(t, func() {
+		for i := 0; i < 3; i++ {
+			if err := api.commandMap("next"); err != nil {
+				t.Errorf("map: %v", err)
+				return
+			}
+		}
+	})
+	out := captureOutput(t, func() {
+		commandRecent()
+	})
+	want := "Page 2:\n  - pastoria-city-area\n  - eterna-forest-area\nPage 3:\n  - mt-coronet-1f\n"
+	if out != want {
+		t.Errorf("recent printed\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestRecentBeforeAnyMap(t *testing.T) {
+	setupTest(t)
+	out := captureOutput(t, func() {
+		commandRecent()
+	})
+	if !strings.Contains(out, "No location pages viewed yet") {
+		t.Errorf("output:\n%s", out)
+	}
+}
+
+func TestScrollbackResize(t *testing.T) {
+	setupTest(t)
+	for page := 1; page <= 4; page++ {
+		pScrollback.Record(page, nil)
+	}
+	captureOutput(t, func() {
+		if err := commandScrollback("2"); err != nil {
+			t.Errorf("scrollback 2: %v", err)
+		}
+		if err := commandScrollback("0"); err == nil {
+			t.Error("scrollback 0 was accepted")
+		}
+	})
+	if len(pScrollback.pages) != 2 || pScrollback.pages[0].number != 3 {
+		t.Errorf("kept pages %+v, want pages 3 and 4", pScrollback.pages)
+	}
+	pScrollback.Record(5, nil)
+	if len(pScrollback.pages) != 2 || pScrollback.pages[1].number != 5 {
+		t.Errorf("kept pages %+v after resizing, want pages 4 and 5", pScrollback.pages)
+	}
+}
+
+func TestPageNumber(t *testing.T) {
+	tests := []struct {
+		url  string
+		want int
+	}{
+		{primaryBaseURL + "/location-area", 1},
+		{primaryBaseURL + "/location-area?offset=20&limit=20", 2},
+		{primaryBaseURL + "/location-area?limit=20&offset=100", 6},
+		{primaryBaseURL + "/location-area?offset=40", 3},
+	}
+	for _, tt := range tests {
+		if got := pageNumber(tt.url); got != tt.want {
+			t.Errorf("pageNumber(%q) = %d, want %d", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// allPokemonURL lists every pokemon in a single, large but static page.
+const allPokemonURL = primaryBaseURL + "/pokemon?limit=100000"
+
+// pokemonNames returns the name of every pokemon, fetched once and cached.
+func pokemonNames() ([]string, error) {
+	body, err := fetchValid(allPokemonURL, validateList)
+	if err != nil {
+		return nil, err
+	}
+	var list PokeList
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		names = append(names, result.Name)
+	}
+	return names, nil
+}
+
+func matchPrefix(names []string, prefix string) []string {
+	matches := make([]string, 0)
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+func matchSubstring(names []string, part string) []string {
+	matches := make([]string, 0)
+	for _, name := range names {
+		if strings.Contains(name, part) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+var errAmbiguousName = errors.New("ambiguous pokemon name")
+
+// disambiguate resolves a partial name to the only name in names containing
+// it. When several do, they are listed and errAmbiguousName is returned.
+func disambiguate(input string, names []string) (string, error) {
+	matches := matchSubstring(names, strings.ToLower(input))
+	switch len(matches) {
+	case 0:
+		fmt.Printf("No pokemon name contains %q\n", input)
+		return "", fmt.Errorf("no pokemon matches %q", input)
+	case 1:
+		fmt.Printf("Assuming you meant %s\n", matches[0])
+		return matches[0], nil
+	}
+	fmt.Printf("Several pokemon match %q, please be more specific:\n", input)
+	printList(matches, maxResults)
+	return "", errAmbiguousName
+}
+
+func commandSearch(params ...string) error {
+	limit, params, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	if len(params) < 1 {
+		fmt.Println("Please provide a name prefix")
+		return errors.New("no prefix provided")
+	}
+	names, err := pokemonNames()
+	if err != nil {
+		return err
+	}
+	matches := matchPrefix(names, strings.ToLower(params[0]))
+	fmt.Printf("Found %d pokemon starting with %q\n", len(matches), params[0])
+	printList(matches, limit)
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testNames = []string{"pichu", "pikachu", "raichu", "pidgey", "pidgeotto", "mew", "mewtwo"}
+
+func TestMatchPrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"pi", []string{"pichu", "pikachu", "pidgey", "pidgeotto"}},
+		{"pidg", []string{"pidgey", "pidgeotto"}},
+		{"mew", []string{"mew", "mewtwo"}},
+		{"chu", []string{}},
+		{"", testNames},
+	}
+	for _, tt := range tests {
+		if got := matchPrefix(testNames, tt.prefix); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matchPrefix(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestSearchFetchesTheListOnce(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{
+		"/pokemon?limit=100000": listJSON(len(testNames), "", testNames...),
+	})
+
+	out := captureOutput(t, func() {
+		for _, prefix := range []string{"Pi", "mew"} {
+			if err := commandSearch(prefix); err != nil {
+				t.Errorf("search %s: %v", prefix, err)
+			}
+		}
+	})
+	for _, want := range []string{
+		`Found 4 pokemon starting with "Pi"`,
+		"  - pikachu",
+		`Found 2 pokemon starting with "mew"`,
+		"  - mewtwo",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "raichu") {
+		t.Errorf("output lists a name without the prefix:\n%s", out)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want 1", n)
+	}
+}
+
+func TestSearchLimit(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon?limit=100000": listJSON(len(testNames), "", testNames...),
+	})
+	out := captureOutput(t, func() {
+		commandSearch("pi", "--limit", "2")
+	})
+	if got := strings.Count(out, "  - "); got != 2 || !strings.Contains(out, "(showing 2 of 4)") {
+		t.Errorf("listed %d names, want 2 and a footer:\n%s", got, out)
+	}
+}
+
+func TestMatchSubstring(t *testing.T) {
+	tests := []struct {
+		part string
+		want []string
+	}{
+		{"chu", []string{"pichu", "pikachu", "raichu"}},
+		{"two", []string{"mewtwo"}},
+		{"geot", []string{"pidgeotto"}},
+		{"zz", []string{}},
+	}
+	for _, tt := range tests {
+		if got := matchSubstring(testNames, tt.part); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matchSubstring(%q) = %v, want %v", tt.part, got, tt.want)
+		}
+	}
+}
+
+func TestDisambiguate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+		output  string
+	}{
+		{"KACH", "pikachu", false, "Assuming you meant pikachu"},
+		{"chu", "", true, "Several pokemon match \"chu\""},
+		{"zz", "", true, "No pokemon name contains \"zz\""},
+	}
+	for _, tt := range tests {
+		var got string
+		var err error
+		out := captureOutput(t, func() {
+			got, err = disambiguate(tt.input, testNames)
+		})
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("disambiguate(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+		if !strings.Contains(out, tt.output) {
+			t.Errorf("disambiguate(%q) printed %q, want %q", tt.input, out, tt.output)
+		}
+	}
+
+	out := captureOutput(t, func() {
+		if _, err := disambiguate("chu", testNames); !errors.Is(err, errAmbiguousName) {
+			t.Errorf("disambiguate(chu) = %v, want errAmbiguousName", err)
+		}
+	})
+	for _, name := range []string{"pichu", "pikachu", "raichu"} {
+		if !strings.Contains(out, "  - "+name) {
+			t.Errorf("the ambiguous matches do not list %s:\n%s", name, out)
+		}
+	}
+}
+
+func TestInspectPartialName(t *testing.T) {
+	setupTest(t)
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	out := captureOutput(t, func() {
+		if err := commandInspect("kach"); err != nil {
+			t.Errorf("inspect kach: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Assuming you meant pikachu") || !strings.Contains(out, "Name: pikachu") {
+		t.Errorf("inspect kach printed:\n%s", out)
+	}
+}
+
+func TestCatchPartialName(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon?limit=100000": listJSON(len(testNames), "", testNames...),
+		"/pokemon/pikachu":      pokemonJSON(25, "pikachu", 112, "electric"),
+	})
+	out := captureOutput(t, func() {
+		if err := commandCatch("kach"); err != nil {
+			t.Errorf("catch kach: %v", err)
+		}
+		if err := commandCatch("chu"); !errors.Is(err, errAmbiguousName) {
+			t.Errorf("catch chu = %v, want errAmbiguousName", err)
+		}
+	})
+	if !strings.Contains(out, "Throwing a Poke Ball at pikachu") {
+		t.Errorf("catch kach did not throw at pikachu:\n%s", out)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ablanchetMD/pokedex/pokecache"
+)
+
+// pokemonPrefix starts the URL of every pokemon.
+const pokemonPrefix = primaryBaseURL + "/pokemon/"
+
+type seenEntry struct {
+	name string
+	url  string
+}
+
+// seenPokemon returns the pokemon cached in c that dex does not hold, sorted
+// by name. Pinned entries ship with the program rather than coming from an
+// encounter, so they are left out.
+func seenPokemon(c *pokecache.Cache, dex *pokedex) []seenEntry {
+	pinned := make(map[string]bool)
+	for _, info := range c.Entries() {
+		pinned[info.Key] = info.Pinned
+	}
+	seen := make([]seenEntry, 0)
+	names := make(map[string]bool)
+	for _, entry := range c.Snapshot() {
+		id, ok := strings.CutPrefix(entry.Key, pokemonPrefix)
+		if !ok || strings.Contains(id, "/") || pinned[entry.Key] {
+			continue
+		}
+		var pokemon Pokemon
+		if err := json.Unmarshal(entry.Data, &pokemon); err != nil || pokemon.Name == "" {
+			continue
+		}
+		if _, err := dex.Get(pokemon.Name); err == nil || names[pokemon.Name] {
+			continue
+		}
+		names[pokemon.Name] = true
+		seen = append(seen, seenEntry{name: pokemon.Name, url: entry.Key})
+	}
+	sort.Slice(seen, func(i, j int) bool {
+		return seen[i].name < seen[j].name
+	})
+	return seen
+}
+
+func commandSeen(params ...string) error {
+	limit, _, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	seen := seenPokemon(pCache, pDex)
+	if len(seen) == 0 {
+		fmt.Println("No uncaught pokemon in the cache")
+		return nil
+	}
+	lines := make([]string, 0, len(seen))
+	for _, entry := range seen {
+		lines = append(lines, fmt.Sprintf("%-16s %s", truncateRunes(entry.name, 16), entry.url))
+	}
+	fmt.Println("Seen but not caught:")
+	printList(lines, limit)
+	return nil
+}
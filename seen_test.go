@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ablanchetMD/pokedex/pokecache"
+)
+
+func TestSeenPokemon(t *testing.T) {
+	setupTest(t)
+	pCache.Add(pokemonPrefix+"133", []byte(pokemonJSON(133, "eevee", 65, "normal")))
+	pCache.Add(pokemonPrefix+"eevee", []byte(pokemonJSON(133, "eevee", 65, "normal")))
+	pCache.Add(pokemonPrefix+"abra", []byte(pokemonJSON(63, "abra", 62, "psychic")))
+	pCache.Add(pokemonPrefix+"pikachu", []byte(pokemonJSON(25, "pikachu", 112, "electric")))
+	pCache.Add(pokemonPrefix+"25/encounters", []byte(`[]`))
+	pCache.Add(primaryBaseURL+"/location-area/1", []byte(locationAreaJSON("canalave-city-area", "tentacool")))
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+
+	var names []string
+	for _, entry := range seenPokemon(pCache, pDex) {
+		names = append(names, entry.name)
+	}
+	if want := []string{"abra", "eevee"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("seenPokemon = %v, want %v", names, want)
+	}
+}
+
+func TestSeenSkipsPinnedEntries(t *testing.T) {
+	if seen := seenPokemon(pokecache.NewCacheFromEmbed(pokecache.Options{}), NewPokedex()); len(seen) != 0 {
+		t.Errorf("seenPokemon listed %d pinned pokemon", len(seen))
+	}
+}
+
+func TestCommandSeen(t *testing.T) {
+	setupTest(t)
+	out := captureOutput(t, func() {
+		if err := commandSeen(); err != nil {
+			t.Errorf("seen: %v", err)
+		}
+	})
+	if out != "No uncaught pokemon in the cache\n" {
+		t.Errorf("seen with an empty cache printed %q", out)
+	}
+
+	pCache.Add(pokemonPrefix+"abra", []byte(pokemonJSON(63, "abra", 62, "psychic")))
+	out = captureOutput(t, func() {
+		if err := commandSeen(); err != nil {
+			t.Errorf("seen: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Seen but not caught:") || !strings.Contains(out, "abra") ||
+		!strings.Contains(out, pokemonPrefix+"abra") {
+		t.Errorf("seen printed:\n%s", out)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// defaultServeAddr is where serve listens when no address is given.
+const defaultServeAddr = "localhost:8080"
+
+// server is the running HTTP server, if any.
+var server *http.Server
+
+// metrics is the body of GET /metrics.
+type metrics struct {
+	CacheHits     uint64  `json:"cache_hits"`
+	CacheMisses   uint64  `json:"cache_misses"`
+	CacheHitRatio float64 `json:"cache_hit_ratio"`
+	Caught        int     `json:"caught"`
+	Commands      int     `json:"commands"`
+	Requests      int64   `json:"requests"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+}
+
+// currentMetrics gathers the tracking counters as of now.
+func currentMetrics(now time.Time) metrics {
+	hits, misses := pCache.Stats()
+	start, commands, _ := currentSession.Counts()
+	return metrics{
+		CacheHits:     hits,
+		CacheMisses:   misses,
+		CacheHitRatio: hitRatio(hits, misses),
+		Caught:        pDex.Count(),
+		Commands:      commands,
+		Requests:      remoteRequests.Load(),
+		UptimeSeconds: now.Sub(start).Seconds(),
+	}
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(currentMetrics(time.Now()))
+}
+
+func newServeMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", handleMetrics)
+	return mux
+}
+
+// commandServe starts an HTTP server in the background so the prompt stays
+// usable.
+func commandServe(params ...string) error {
+	if server != nil {
+		fmt.Println("Already serving on", server.Addr)
+		return nil
+	}
+	addr := defaultServeAddr
+	if len(params) > 0 {
+		addr = params[0]
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Println("Error starting server:", err)
+		return err
+	}
+	server = &http.Server{Addr: listener.Addr().String(), Handler: newServeMux()}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println("Server stopped:", err)
+		}
+	}()
+	fmt.Printf("Serving on http://%s (GET /metrics)\n", server.Addr)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCurrentMetrics(t *testing.T) {
+	clock := setupTest(t)
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	pCache.Add("https://pokeapi.co/api/v2/pokemon/25", []byte("{}"))
+	pCache.Get("https://pokeapi.co/api/v2/pokemon/25")
+	pCache.Get("https://pokeapi.co/api/v2/pokemon/1")
+	pCache.Get("https://pokeapi.co/api/v2/pokemon/4")
+	currentSession.CountCommand()
+	currentSession.CountCommand()
+	remoteRequests.Store(0)
+
+	got := currentMetrics(clock.Now().Add(90 * time.Second))
+	want := metrics{
+		CacheHits:     1,
+		CacheMisses:   2,
+		CacheHitRatio: hitRatio(1, 2),
+		Caught:        1,
+		Commands:      2,
+		UptimeSeconds: 90,
+	}
+	if got != want {
+		t.Errorf("currentMetrics = %+v, want %+v", got, want)
+	}
+}
+
+func TestHandleMetrics(t *testing.T) {
+	setupTest(t)
+	catchPokemon(t, pokemonJSON(25, "pikachu", 112, "electric"))
+	mux := newServeMux()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET /metrics body %q: %v", rec.Body, err)
+	}
+	for _, key := range []string{"cache_hits", "cache_misses", "cache_hit_ratio", "caught", "commands", "requests", "uptime_seconds"} {
+		if _, ok := body[key]; !ok {
+			t.Errorf("metrics lack %q: %s", key, rec.Body)
+		}
+	}
+	if body["caught"] != 1.0 {
+		t.Errorf("caught = %v, want 1", body["caught"])
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /metrics = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestCommandServe(t *testing.T) {
+	setupTest(t)
+	t.Cleanup(func() {
+		if server != nil {
+			server.Close()
+			server = nil
+		}
+	})
+	out := captureOutput(t, func() {
+		if err := commandServe("127.0.0.1:0"); err != nil {
+			t.Errorf("serve: %v", err)
+		}
+		commandServe("127.0.0.1:0")
+	})
+	if server == nil {
+		t.Fatal("serve did not start a server")
+	}
+	if !strings.Contains(out, "Serving on http://"+server.Addr) || !strings.Contains(out, "Already serving on "+server.Addr) {
+		t.Errorf("serve printed:\n%s", out)
+	}
+
+	resp, err := http.Get("http://" + server.Addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var m metrics
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /metrics = %s, %v", resp.Status, err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// session tracks what happened since the program started. It is safe for
+// concurrent use, e.g. by the metrics endpoint of serve.
+type session struct {
+	mu       sync.Mutex
+	start    time.Time
+	commands int
+	caught   int
+}
+
+var currentSession = &session{start: time.Now()}
+
+// CountCommand records that a command was run.
+func (s *session) CountCommand() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands++
+}
+
+// CountCatch records that a pokemon was caught.
+func (s *session) CountCatch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.caught++
+}
+
+// Reset clears the counters and restarts the session at now.
+func (s *session) Reset(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start = now
+	s.commands = 0
+	s.caught = 0
+}
+
+// Counts returns the session start and its counters.
+func (s *session) Counts() (start time.Time, commands, caught int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.start, s.commands, s.caught
+}
+
+// Summary formats the farewell shown on exit.
+func (s *session) Summary(hits, misses uint64, now time.Time) string {
+	start, commands, caught := s.Counts()
+	var b strings.Builder
+	fmt.Fprintln(&b, "Session summary:")
+	fmt.Fprintf(&b, "  Commands run: %d\n", commands)
+	fmt.Fprintf(&b, "  Pokemon caught: %d\n", caught)
+	fmt.Fprintf(&b, "  Cache hit ratio: %.1f%%\n", hitRatio(hits, misses)*100)
+	fmt.Fprintf(&b, "  Duration: %s\n", now.Sub(start).Round(time.Second))
+	return b.String()
+}
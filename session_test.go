@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionSummary(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := &session{start: start}
+	for i := 0; i < 7; i++ {
+		s.CountCommand()
+	}
+	s.CountCatch()
+	s.CountCatch()
+
+	got := s.Summary(3, 1, start.Add(5*time.Minute+12*time.Second+400*time.Millisecond))
+	want := "Session summary:\n" +
+		"  Commands run: 7\n" +
+		"  Pokemon caught: 2\n" +
+		"  Cache hit ratio: 75.0%\n" +
+		"  Duration: 5m12s\n"
+	if got != want {
+		t.Errorf("Summary() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSessionReset(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := &session{start: start}
+	s.CountCommand()
+	s.CountCatch()
+	later := start.Add(time.Hour)
+	s.Reset(later)
+	if gotStart, commands, caught := s.Counts(); !gotStart.Equal(later) || commands != 0 || caught != 0 {
+		t.Errorf("Counts() after Reset = %v, %d, %d, want %v, 0, 0", gotStart, commands, caught, later)
+	}
+}
+
+func TestPrintSessionSummary(t *testing.T) {
+	setupTest(t)
+	out := captureOutput(t, func() {
+		dispatch("dicesides 12")
+		dispatch("movelimit 3")
+		printSessionSummary()
+	})
+	if !strings.Contains(out, "Session summary:\n  Commands run: 2\n") {
+		t.Errorf("summary printed:\n%s", out)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// diffSets splits two lists of names into those only found in a, those only
+// found in b, and those found in both, each sorted and without duplicates.
+func diffSets(a, b []string) (onlyA, onlyB, shared []string) {
+	inA := make(map[string]bool, len(a))
+	for _, name := range a {
+		inA[name] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, name := range b {
+		inB[name] = true
+	}
+	for name := range inA {
+		if inB[name] {
+			shared = append(shared, name)
+		} else {
+			onlyA = append(onlyA, name)
+		}
+	}
+	for name := range inB {
+		if !inA[name] {
+			onlyB = append(onlyB, name)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	sort.Strings(shared)
+	return onlyA, onlyB, shared
+}
+
+// printNameGroup prints a titled, counted group of names, such as one of the
+// lists diffSets returns.
+func printNameGroup(title string, names []string) {
+	fmt.Printf("%s (%d):\n", title, len(names))
+	if len(names) > 0 {
+		fmt.Println("  " + strings.Join(names, ", "))
+	}
+}
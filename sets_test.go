@@ -0,0 +1,22 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSets(t *testing.T) {
+	onlyA, onlyB, shared := diffSets(
+		[]string{"tentacool", "staryu", "wingull", "tentacool"},
+		[]string{"wingull", "shellos", "tentacool"},
+	)
+	if want := []string{"staryu"}; !reflect.DeepEqual(onlyA, want) {
+		t.Errorf("only in the first = %v, want %v", onlyA, want)
+	}
+	if want := []string{"shellos"}; !reflect.DeepEqual(onlyB, want) {
+		t.Errorf("only in the second = %v, want %v", onlyB, want)
+	}
+	if want := []string{"tentacool", "wingull"}; !reflect.DeepEqual(shared, want) {
+		t.Errorf("shared = %v, want %v", shared, want)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+func spriteURL(pokemon Pokemon) (string, bool) {
+	if pokemon.Sprites.FrontDefault == nil || *pokemon.Sprites.FrontDefault == "" {
+		return "", false
+	}
+	return *pokemon.Sprites.FrontDefault, true
+}
+
+// downloadFile saves the resource at url to path.
+func downloadFile(url, path string) error {
+	if offline {
+		return errOffline
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := sendRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, resp.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func commandSprite(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a Pokemon name")
+		return errors.New("no Pokemon name provided")
+	}
+	pokemon, err := pDex.GetPokemon(params[0])
+	if err != nil {
+		fmt.Println("You have not caught that pokemon yet (or there was an error):", params[0])
+		return err
+	}
+	url, ok := spriteURL(pokemon)
+	if !ok {
+		fmt.Println("No sprite available for", pokemon.Name)
+		return nil
+	}
+	fmt.Printf("%s's sprite: %s\n", pokemon.Name, url)
+	if len(params) < 2 {
+		return nil
+	}
+	err = downloadFile(url, params[1])
+	if err != nil {
+		fmt.Println("Error downloading sprite:", err)
+		return err
+	}
+	fmt.Println("Sprite saved to", params[1])
+	return nil
+}
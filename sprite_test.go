@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withSprite returns body with its front_default sprite set to url; an
+// empty url leaves it null.
+func withSprite(t *testing.T, body, url string) string {
+	t.Helper()
+	var pokemon map[string]any
+	if err := json.Unmarshal([]byte(body), &pokemon); err != nil {
+		t.Fatal(err)
+	}
+	sprites := map[string]any{"front_default": nil, "front_shiny": nil}
+	if url != "" {
+		sprites["front_default"] = url
+	}
+	pokemon["sprites"] = sprites
+	data, err := json.Marshal(pokemon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSpriteURL(t *testing.T) {
+	setupTest(t)
+	const url = "https://raw.githubusercontent.com/PokeAPI/sprites/master/sprites/pokemon/25.png"
+	catchPokemon(t, withSprite(t, pokemonJSON(25, "pikachu", 112, "electric"), url))
+	catchPokemon(t, withSprite(t, pokemonJSON(10093, "missing", 1, "normal"), ""))
+
+	out := captureOutput(t, func() {
+		if err := commandSprite("pikachu"); err != nil {
+			t.Errorf("sprite pikachu: %v", err)
+		}
+		if err := commandSprite("missing"); err != nil {
+			t.Errorf("sprite missing: %v", err)
+		}
+	})
+	for _, want := range []string{"pikachu's sprite: " + url, "No sprite available for missing"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSpriteDownload(t *testing.T) {
+	setupTest(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "PNG")
+	}))
+	defer srv.Close()
+	catchPokemon(t, withSprite(t, pokemonJSON(25, "pikachu", 112, "electric"), srv.URL+"/25.png"))
+
+	path := filepath.Join(t.TempDir(), "pikachu.png")
+	sent := remoteRequests.Load()
+	captureOutput(t, func() {
+		if err := commandSprite("pikachu", path); err != nil {
+			t.Errorf("sprite pikachu %s: %v", path, err)
+		}
+	})
+	if data, err := os.ReadFile(path); err != nil || string(data) != "PNG" {
+		t.Errorf("saved %q, %v, want the served image", data, err)
+	}
+	if n := remoteRequests.Load() - sent; n != 1 {
+		t.Errorf("counted %d requests for the download, want 1", n)
+	}
+}
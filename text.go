@@ -0,0 +1,18 @@
+package main
+
+// truncateRunes shortens s to at most n runes, ending it with an ellipsis
+// when it was cut. It counts runes rather than bytes so multibyte names are
+// never split mid-character. A limit of zero or less yields "".
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-1]) + "…"
+}
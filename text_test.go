@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"pikachu", 10, "pikachu"},
+		{"pikachu", 7, "pikachu"},
+		{"pikachu", 6, "pikac…"},
+		{"pikachu", 1, "p"},
+		{"pikachu", 0, ""},
+		{"pikachu", -1, ""},
+		{"", -1, ""},
+		{"flabébé", 7, "flabébé"},
+		{"flabébé", 6, "flabé…"},
+		{"flabébé", 5, "flab…"},
+		{"ニドラン♀", 3, "ニド…"},
+		{"", 3, ""},
+	}
+	for _, tt := range tests {
+		got := truncateRunes(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) || utf8.RuneCountInString(got) > max(tt.n, 0) {
+			t.Errorf("truncateRunes(%q, %d) = %q, not at most %d valid runes", tt.s, tt.n, got, tt.n)
+		}
+	}
+}
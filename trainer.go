@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ablanchetMD/pokedex/pokecache"
+)
+
+// maxLevel is the highest level a trainer can reach.
+const maxLevel = 100
+
+// luckyEggDuration is how long a lucky egg doubles XP.
+const luckyEggDuration = 5 * time.Minute
+
+// defaultTrainerName is how the player is addressed until they give a name.
+const defaultTrainerName = "Trainer"
+
+// streakBonusStep is the extra XP, in percent, each consecutive catch adds,
+// up to maxStreakBonus.
+const (
+	streakBonusStep = 10
+	maxStreakBonus  = 50
+)
+
+// trainer holds the player's progression. It is saved with the profile after
+// every change.
+type trainer struct {
+	// Name is how greetings address the player, see DisplayName.
+	Name          string    `json:"name,omitempty"`
+	XP            int       `json:"xp"`
+	LuckyEggUntil time.Time `json:"lucky_egg_until"`
+	// Streak counts the catches since the last escape.
+	Streak int `json:"streak"`
+	clock  pokecache.Clock
+}
+
+var pTrainer *trainer
+
+func newTrainer() *trainer {
+	return &trainer{clock: pokecache.RealClock}
+}
+
+// defaultTrainerPath is where the trainer was saved before it moved into the
+// profile, see LoadProfile.
+func defaultTrainerPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pokedex_trainer.json")
+}
+
+// loadTrainer reads the trainer saved at path. A missing file yields a new
+// trainer.
+func loadTrainer(path string) (*trainer, error) {
+	t := newTrainer()
+	if path == "" {
+		return t, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return t, nil
+		}
+		return t, err
+	}
+	err = json.Unmarshal(data, t)
+	return t, err
+}
+
+// DisplayName returns the trainer's name, or defaultTrainerName when unset.
+func (t *trainer) DisplayName() string {
+	if t.Name == "" {
+		return defaultTrainerName
+	}
+	return t.Name
+}
+
+// greeting welcomes t at startup; returning trainers are welcomed back.
+func greeting(t *trainer, returning bool) string {
+	if returning {
+		return fmt.Sprintf("Welcome back, %s!", t.DisplayName())
+	}
+	return fmt.Sprintf("Welcome, %s!", t.DisplayName())
+}
+
+// welcome greets the trainer at the start of an interactive session. A
+// first-time trainer is asked their name, unless POKEDEX_TRAINER_NAME
+// already gave one.
+func welcome() {
+	returning := profileSaved()
+	if !returning && pTrainer.Name == "" {
+		askTrainerName(pTrainer)
+	}
+	fmt.Println(greeting(pTrainer, returning))
+}
+
+// askTrainerName prompts for the trainer's name on first run and saves it.
+// An empty answer saves defaultTrainerName, so the question is not asked
+// again.
+func askTrainerName(t *trainer) {
+	answer, err := pEditor.ReadLine("What is your name, trainer? ")
+	if err != nil {
+		return
+	}
+	t.Name = strings.TrimSpace(answer)
+	if t.Name == "" {
+		t.Name = defaultTrainerName
+	}
+	if err := t.Save(); err != nil {
+		fmt.Println("Error saving trainer:", err)
+	}
+}
+
+func (t *trainer) Save() error {
+	return saveProfile()
+}
+
+// AddXP awards xp to the trainer, doubled while a lucky egg is active, and
+// saves the result. It returns the XP actually gained.
+func (t *trainer) AddXP(xp int) (int, error) {
+	if t.LuckyEggActive() {
+		xp *= 2
+	}
+	t.XP += xp
+	return xp, t.Save()
+}
+
+// streakBonus returns the XP bonus, in percent, for a catch made after
+// streak consecutive catches.
+func streakBonus(streak int) int {
+	return min(streak*streakBonusStep, maxStreakBonus)
+}
+
+// RecordCatch extends the catch streak and awards xp plus the streak bonus,
+// see AddXP. It returns the XP gained and the bonus applied, in percent.
+func (t *trainer) RecordCatch(xp int) (int, int, error) {
+	bonus := streakBonus(t.Streak)
+	t.Streak++
+	gained, err := t.AddXP(xp + xp*bonus/100)
+	return gained, bonus, err
+}
+
+// RecordEscape ends the catch streak.
+func (t *trainer) RecordEscape() error {
+	if t.Streak == 0 {
+		return nil
+	}
+	t.Streak = 0
+	return t.Save()
+}
+
+// LuckyEggActive reports whether a lucky egg is currently doubling XP.
+func (t *trainer) LuckyEggActive() bool {
+	return t.clock.Now().Before(t.LuckyEggUntil)
+}
+
+// LuckyEggRemaining returns how long the current lucky egg lasts, or zero.
+func (t *trainer) LuckyEggRemaining() time.Duration {
+	if !t.LuckyEggActive() {
+		return 0
+	}
+	return t.LuckyEggUntil.Sub(t.clock.Now())
+}
+
+// ToggleLuckyEgg starts a lucky egg, or stops the active one. It reports
+// whether an egg is active afterwards.
+func (t *trainer) ToggleLuckyEgg() (bool, error) {
+	if t.LuckyEggActive() {
+		t.LuckyEggUntil = time.Time{}
+	} else {
+		t.LuckyEggUntil = t.clock.Now().Add(luckyEggDuration)
+	}
+	return t.LuckyEggActive(), t.Save()
+}
+
+// levelForXP follows a cubic curve: reaching level n takes n^3 XP.
+func levelForXP(xp int) int {
+	level := 1
+	for level < maxLevel && xpForLevel(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+func xpForLevel(level int) int {
+	return level * level * level
+}
+
+func commandTrainer(params ...string) error {
+	level := levelForXP(pTrainer.XP)
+	fmt.Printf("Name: %s\n", pTrainer.DisplayName())
+	fmt.Printf("XP: %d\n", pTrainer.XP)
+	fmt.Printf("Level: %d\n", level)
+	if level < maxLevel {
+		fmt.Printf("Next level in: %d XP\n", xpForLevel(level+1)-pTrainer.XP)
+	}
+	if pTrainer.Streak > 0 {
+		fmt.Printf("Catch streak: %d (next catch +%d%% XP)\n", pTrainer.Streak, streakBonus(pTrainer.Streak))
+	}
+	if remaining := pTrainer.LuckyEggRemaining(); remaining > 0 {
+		fmt.Printf("Lucky egg: %s left\n", remaining.Round(time.Second))
+	}
+	return nil
+}
+
+func commandLuckyEgg(params ...string) error {
+	active, err := pTrainer.ToggleLuckyEgg()
+	if err != nil {
+		fmt.Println("Error saving trainer:", err)
+		return err
+	}
+	if active {
+		fmt.Printf("Lucky egg active! XP is doubled for %s.\n", luckyEggDuration)
+	} else {
+		fmt.Println("Lucky egg put away.")
+	}
+	return nil
+}
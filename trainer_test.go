@@ -0,0 +1,251 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCatchAwardsBaseExperience(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/bulbasaur": pokemonJSON(1, "bulbasaur", 64, "grass"),
+		"/pokemon/squirtle":  pokemonJSON(7, "squirtle", 63, "water"),
+	})
+
+	out := captureOutput(t, func() {
+		for _, name := range []string{"bulbasaur", "squirtle"} {
+			if err := commandCatch(name, "--ball", "master"); err != nil {
+				t.Errorf("catch %s: %v", name, err)
+			}
+		}
+	})
+	// The second catch extends the streak, worth 10% more.
+	if want := 64 + 63 + 6; pTrainer.XP != want {
+		t.Errorf("XP = %d, want %d", pTrainer.XP, want)
+	}
+	if !strings.Contains(out, "You gained 64 XP!") || !strings.Contains(out, "You gained 69 XP!") {
+		t.Errorf("output does not report the XP gained:\n%s", out)
+	}
+	if level := levelForXP(pTrainer.XP); level != 5 {
+		t.Errorf("level for %d XP = %d, want 5", pTrainer.XP, level)
+	}
+}
+
+func TestLevelForXP(t *testing.T) {
+	tests := []struct {
+		xp, level int
+	}{
+		{0, 1},
+		{7, 1},
+		{8, 2},
+		{26, 2},
+		{27, 3},
+		{124, 4},
+		{125, 5},
+		{999999, 99},
+		{1000000, maxLevel},
+		{1 << 40, maxLevel},
+	}
+	for _, tt := range tests {
+		if got := levelForXP(tt.xp); got != tt.level {
+			t.Errorf("levelForXP(%d) = %d, want %d", tt.xp, got, tt.level)
+		}
+	}
+}
+
+func TestCommandTrainer(t *testing.T) {
+	setupTest(t)
+	pTrainer.XP = 30
+	out := captureOutput(t, func() {
+		commandTrainer()
+	})
+	for _, want := range []string{"XP: 30", "Level: 3", "Next level in: 34 XP"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestLuckyEggDoublesXPUntilItExpires(t *testing.T) {
+	clock := setupTest(t)
+	if active, err := pTrainer.ToggleLuckyEgg(); !active || err != nil {
+		t.Fatalf("ToggleLuckyEgg = %v, %v, want an active egg", active, err)
+	}
+
+	if gained, _ := pTrainer.AddXP(50); gained != 100 {
+		t.Errorf("gained %d XP with the egg active, want 100", gained)
+	}
+	clock.Advance(luckyEggDuration - time.Second)
+	if remaining := pTrainer.LuckyEggRemaining(); remaining != time.Second {
+		t.Errorf("LuckyEggRemaining = %s, want 1s", remaining)
+	}
+	if gained, _ := pTrainer.AddXP(10); gained != 20 {
+		t.Errorf("gained %d XP just before expiry, want 20", gained)
+	}
+
+	clock.Advance(time.Second)
+	if pTrainer.LuckyEggActive() {
+		t.Error("lucky egg still active after its duration")
+	}
+	if gained, _ := pTrainer.AddXP(50); gained != 50 {
+		t.Errorf("gained %d XP after expiry, want 50", gained)
+	}
+	if pTrainer.XP != 170 {
+		t.Errorf("XP = %d, want 170", pTrainer.XP)
+	}
+}
+
+func TestLuckyEggToggleOff(t *testing.T) {
+	setupTest(t)
+	pTrainer.ToggleLuckyEgg()
+	if active, _ := pTrainer.ToggleLuckyEgg(); active {
+		t.Error("second toggle left the egg active")
+	}
+	if gained, _ := pTrainer.AddXP(50); gained != 50 {
+		t.Errorf("gained %d XP after putting the egg away, want 50", gained)
+	}
+}
+
+func TestStreakBonus(t *testing.T) {
+	tests := []struct {
+		streak, bonus int
+	}{
+		{0, 0},
+		{1, 10},
+		{4, 40},
+		{5, maxStreakBonus},
+		{50, maxStreakBonus},
+	}
+	for _, tt := range tests {
+		if got := streakBonus(tt.streak); got != tt.bonus {
+			t.Errorf("streakBonus(%d) = %d, want %d", tt.streak, got, tt.bonus)
+		}
+	}
+}
+
+func TestRecordCatchStreak(t *testing.T) {
+	setupTest(t)
+	var gains, bonuses []int
+	for i := 0; i < 7; i++ {
+		gained, bonus, err := pTrainer.RecordCatch(100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gains = append(gains, gained)
+		bonuses = append(bonuses, bonus)
+	}
+	if want := []int{100, 110, 120, 130, 140, 150, 150}; !reflect.DeepEqual(gains, want) {
+		t.Errorf("XP gained = %v, want %v", gains, want)
+	}
+	if want := []int{0, 10, 20, 30, 40, 50, 50}; !reflect.DeepEqual(bonuses, want) {
+		t.Errorf("bonuses = %v, want %v", bonuses, want)
+	}
+	if pTrainer.Streak != 7 {
+		t.Errorf("Streak = %d, want 7", pTrainer.Streak)
+	}
+
+	if err := pTrainer.RecordEscape(); err != nil || pTrainer.Streak != 0 {
+		t.Errorf("RecordEscape = %v, left a streak of %d", err, pTrainer.Streak)
+	}
+	if gained, bonus, _ := pTrainer.RecordCatch(100); gained != 100 || bonus != 0 {
+		t.Errorf("first catch after an escape gained %d XP with a %d%% bonus", gained, bonus)
+	}
+}
+
+func TestStreakStacksWithLuckyEgg(t *testing.T) {
+	setupTest(t)
+	pTrainer.Streak = 2
+	pTrainer.ToggleLuckyEgg()
+	if gained, bonus, _ := pTrainer.RecordCatch(100); gained != 240 || bonus != 20 {
+		t.Errorf("RecordCatch with a streak and an egg = %d XP, %d%% bonus, want 240 and 20", gained, bonus)
+	}
+}
+
+func TestGreeting(t *testing.T) {
+	tests := []struct {
+		name      string
+		returning bool
+		want      string
+	}{
+		{"", false, "Welcome, Trainer!"},
+		{"", true, "Welcome back, Trainer!"},
+		{"Ash", false, "Welcome, Ash!"},
+		{"Ash", true, "Welcome back, Ash!"},
+	}
+	for _, tt := range tests {
+		tr := newTrainer()
+		tr.Name = tt.name
+		if got := greeting(tr, tt.returning); got != tt.want {
+			t.Errorf("greeting(%q, %v) = %q, want %q", tt.name, tt.returning, got, tt.want)
+		}
+	}
+}
+
+func TestAskTrainerName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		saved bool
+	}{
+		{"  Ash  \n", "Ash", true},
+		{"\n", "Trainer", true},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		setupTest(t)
+		profilePath = filepath.Join(t.TempDir(), "profile.json")
+		pEditor = pipedEditor(tt.input)
+		captureOutput(t, func() { askTrainerName(pTrainer) })
+		if pTrainer.Name != tt.want {
+			t.Errorf("answering %q set the name to %q, want %q", tt.input, pTrainer.Name, tt.want)
+		}
+		_, err := os.Stat(profilePath)
+		if saved := err == nil; saved != tt.saved {
+			t.Errorf("answering %q saved the profile: %v, want %v", tt.input, saved, tt.saved)
+		}
+		if tt.saved {
+			_, tr, _, err := LoadProfile(profilePath, pCache)
+			if err != nil || tr.DisplayName() != tt.want {
+				t.Errorf("saved profile has the name %q, %v, want %q", tr.DisplayName(), err, tt.want)
+			}
+		}
+	}
+}
+
+func TestWelcome(t *testing.T) {
+	// Every case has "Gary" to answer with, so the greeting shows whether
+	// the name was asked.
+	tests := []struct {
+		name      string
+		savedName string
+		saved     bool
+		envName   string
+		want      string
+	}{
+		{"first run", "", false, "", "Welcome, Gary!"},
+		{"first run with POKEDEX_TRAINER_NAME", "", false, "Misty", "Welcome, Misty!"},
+		{"returning", "Brock", true, "", "Welcome back, Brock!"},
+		{"returning after a blank answer", defaultTrainerName, true, "", "Welcome back, Trainer!"},
+	}
+	for _, tt := range tests {
+		setupTest(t)
+		// Nothing to migrate for the first runs.
+		t.Setenv("HOME", t.TempDir())
+		path := filepath.Join(t.TempDir(), "profile.json")
+		if tt.saved {
+			pTrainer.Name = tt.savedName
+			if err := SaveProfile(path, pDex, pTrainer); err != nil {
+				t.Fatal(err)
+			}
+		}
+		openProfile(path, tt.envName)
+		pEditor = pipedEditor("Gary\n")
+		if out := captureOutput(t, welcome); out != tt.want+"\n" {
+			t.Errorf("%s: welcome printed %q, want %q", tt.name, out, tt.want)
+		}
+	}
+}
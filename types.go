@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type namedResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type PokeType struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	DamageRelations struct {
+		DoubleDamageTo   []namedResource `json:"double_damage_to"`
+		HalfDamageTo     []namedResource `json:"half_damage_to"`
+		NoDamageTo       []namedResource `json:"no_damage_to"`
+		DoubleDamageFrom []namedResource `json:"double_damage_from"`
+		HalfDamageFrom   []namedResource `json:"half_damage_from"`
+		NoDamageFrom     []namedResource `json:"no_damage_from"`
+	} `json:"damage_relations"`
+}
+
+// allTypesURL lists every type on a single page.
+const allTypesURL = primaryBaseURL + "/type?limit=100"
+
+// typeNames returns the name of every type, fetched once and cached.
+func typeNames() ([]string, error) {
+	body, err := fetchValid(allTypesURL, validateList)
+	if err != nil {
+		return nil, err
+	}
+	var list PokeList
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		names = append(names, result.Name)
+	}
+	return names, nil
+}
+
+// checkTypeName returns an error naming the valid types when name is not
+// one of them.
+func checkTypeName(name string) error {
+	names, err := typeNames()
+	if err != nil {
+		return err
+	}
+	for _, known := range names {
+		if known == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown type %q, try one of: %s", name, strings.Join(names, ", "))
+}
+
+func commandTypes(params ...string) error {
+	limit, _, err := takeLimit(params, maxResults)
+	if err != nil {
+		return err
+	}
+	names, err := typeNames()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Types:")
+	printList(names, limit)
+	return nil
+}
+
+func validateType(data []byte) error {
+	return requireFields(data, "id", "name", "damage_relations")
+}
+
+// fetchType returns the type called name, fetched through the cache.
+func fetchType(name string) (PokeType, error) {
+	var pokeType PokeType
+	body, err := fetchValid(primaryBaseURL+"/type/"+name, validateType)
+	if err != nil {
+		return pokeType, err
+	}
+	err = json.Unmarshal(body, &pokeType)
+	if err != nil {
+		fmt.Println("Error unmarshalling JSON:", err)
+	}
+	return pokeType, err
+}
+
+func resourceNames(resources []namedResource) string {
+	if len(resources) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func commandTypeChart(params ...string) error {
+	if len(params) < 1 {
+		fmt.Println("Please provide a type name")
+		return errors.New("no type name provided")
+	}
+	name := strings.ToLower(params[0])
+	err := checkTypeName(name)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	pokeType, err := fetchType(name)
+	if err != nil {
+		return err
+	}
+	relations := pokeType.DamageRelations
+	fmt.Printf("Type: %s\n", pokeType.Name)
+	fmt.Println("Attacking:")
+	fmt.Println("  Super effective against:", resourceNames(relations.DoubleDamageTo))
+	fmt.Println("  Not very effective against:", resourceNames(relations.HalfDamageTo))
+	fmt.Println("  No effect on:", resourceNames(relations.NoDamageTo))
+	fmt.Println("Defending:")
+	fmt.Println("  Weak to:", resourceNames(relations.DoubleDamageFrom))
+	fmt.Println("  Resists:", resourceNames(relations.HalfDamageFrom))
+	fmt.Println("  Immune to:", resourceNames(relations.NoDamageFrom))
+	return nil
+}
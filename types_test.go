@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var testTypes = []string{"normal", "fire", "water", "electric", "grass", "ground", "flying", "dragon"}
+
+// typeJSON returns a type body with the given damage relations, keyed by
+// their pokeapi field names, e.g. "double_damage_to".
+func typeJSON(name string, relations map[string][]string) string {
+	damage := make(map[string][]namedResource)
+	for _, field := range []string{"double_damage_to", "half_damage_to", "no_damage_to", "double_damage_from", "half_damage_from", "no_damage_from"} {
+		damage[field] = []namedResource{}
+		for _, other := range relations[field] {
+			damage[field] = append(damage[field], namedResource{Name: other, URL: primaryBaseURL + "/type/" + other})
+		}
+	}
+	data, err := json.Marshal(map[string]any{"id": 1, "name": name, "damage_relations": damage})
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// stubTypes serves the type list and the electric type.
+func stubTypes(t *testing.T) {
+	t.Helper()
+	stubAPI(t, map[string]string{
+		"/type?limit=100": listJSON(len(testTypes), "", testTypes...),
+		"/type/electric": typeJSON("electric", map[string][]string{
+			"double_damage_to":   {"water", "flying"},
+			"half_damage_to":     {"grass", "electric", "dragon"},
+			"no_damage_to":       {"ground"},
+			"double_damage_from": {"ground"},
+			"half_damage_from":   {"flying", "electric"},
+		}),
+	})
+}
+
+func TestTypeChart(t *testing.T) {
+	setupTest(t)
+	stubTypes(t)
+	out := captureOutput(t, func() {
+		if err := commandTypeChart("Electric"); err != nil {
+			t.Errorf("typechart: %v", err)
+		}
+	})
+	want := "Type: electric\n" +
+		"Attacking:\n" +
+		"  Super effective against: water, flying\n" +
+		"  Not very effective against: grass, electric, dragon\n" +
+		"  No effect on: ground\n" +
+		"Defending:\n" +
+		"  Weak to: ground\n" +
+		"  Resists: flying, electric\n" +
+		"  Immune to: none\n"
+	if out != want {
+		t.Errorf("typechart printed\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestTypeChartUnknownType(t *testing.T) {
+	setupTest(t)
+	stubTypes(t)
+	out := captureOutput(t, func() {
+		if err := commandTypeChart("sound"); err == nil {
+			t.Error("typechart accepted an unknown type")
+		}
+	})
+	if !strings.Contains(out, `unknown type "sound", try one of: normal, fire`) {
+		t.Errorf("output does not list the valid types:\n%s", out)
+	}
+}
+
+func TestTypesListsEveryType(t *testing.T) {
+	setupTest(t)
+	stubTypes(t)
+	out := captureOutput(t, func() {
+		if err := commandTypes(); err != nil {
+			t.Errorf("types: %v", err)
+		}
+	})
+	want := "Types:\n"
+	for _, name := range testTypes {
+		want += "  - " + name + "\n"
+	}
+	if out != want {
+		t.Errorf("types printed\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestCheckTypeName(t *testing.T) {
+	setupTest(t)
+	requests := stubAPI(t, map[string]string{
+		"/type?limit=100": listJSON(len(testTypes), "", testTypes...),
+	})
+	if err := checkTypeName("fire"); err != nil {
+		t.Errorf("checkTypeName(fire): %v", err)
+	}
+	if err := checkTypeName("Fire"); err == nil {
+		t.Error("checkTypeName accepted a name that is not lower case")
+	}
+	if err := checkTypeName("sound"); err == nil {
+		t.Error("checkTypeName accepted an unknown type")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want the type list fetched once", n)
+	}
+}
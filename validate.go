@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// requireFields checks that data is a JSON object holding every field.
+func requireFields(data []byte, fields ...string) error {
+	var object map[string]json.RawMessage
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if _, ok := object[field]; !ok {
+			return fmt.Errorf("unexpected response: missing %q", field)
+		}
+	}
+	return nil
+}
+
+func validatePokemon(data []byte) error {
+	return requireFields(data, "id", "name", "base_experience", "stats", "types")
+}
+
+func validateLocationArea(data []byte) error {
+	return requireFields(data, "id", "name", "pokemon_encounters")
+}
+
+func validateList(data []byte) error {
+	return requireFields(data, "count", "results")
+}
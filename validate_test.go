@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestValidateRejectsWrongShape(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func([]byte) error
+		body     string
+		ok       bool
+	}{
+		{"pokemon", validatePokemon, pokemonJSON(25, "pikachu", 112, "electric"), true},
+		{"pokemon without stats", validatePokemon, `{"id":25,"name":"pikachu","base_experience":112,"types":[]}`, false},
+		{"pokemon given a list", validatePokemon, listJSON(1, "", "pikachu"), false},
+		{"location area", validateLocationArea, `{"id":1,"name":"canalave-city-area","pokemon_encounters":[]}`, true},
+		{"location area without encounters", validateLocationArea, `{"id":1,"name":"canalave-city-area"}`, false},
+		{"list", validateList, listJSON(1, "", "pikachu"), true},
+		{"list without results", validateList, `{"count":1}`, false},
+		{"array", validateList, `[]`, false},
+	}
+	for _, tt := range tests {
+		err := tt.validate([]byte(tt.body))
+		if tt.ok && err != nil {
+			t.Errorf("%s: rejected a valid body: %v", tt.name, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: accepted %s", tt.name, tt.body)
+		}
+	}
+}
+
+func TestFetchDoesNotCacheWrongShape(t *testing.T) {
+	setupTest(t)
+	stubAPI(t, map[string]string{
+		"/pokemon/pikachu":                  `{"name":"pikachu"}`,
+		"/location-area/canalave-city-area": `{"count":0,"results":[]}`,
+	})
+	captureOutput(t, func() {
+		if _, err := fetchValid(primaryBaseURL+"/pokemon/pikachu", validatePokemon); err == nil {
+			t.Error("fetched a pokemon without its fields")
+		}
+		if _, err := fetchValid(primaryBaseURL+"/location-area/canalave-city-area", validateLocationArea); err == nil {
+			t.Error("fetched a list in place of a location area")
+		}
+	})
+	if n := len(pCache.Entries()); n != 0 {
+		t.Errorf("cache holds %d entries, want none", n)
+	}
+}
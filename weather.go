@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// weatherBoost multiplies the odds of catching a pokemon whose type the
+// current weather favors.
+const weatherBoost = 1.25
+
+// weather favors the pokemon of some types for the whole session.
+type weather struct {
+	name   string
+	boosts []string
+}
+
+var weathers = []weather{
+	{name: "sunny", boosts: []string{"fire", "grass", "ground"}},
+	{name: "rain", boosts: []string{"water", "electric", "bug"}},
+	{name: "snow", boosts: []string{"ice", "steel"}},
+	{name: "windy", boosts: []string{"dragon", "flying", "psychic"}},
+	{name: "fog", boosts: []string{"dark", "ghost"}},
+	{name: "cloudy", boosts: []string{"fairy", "fighting", "poison"}},
+	{name: "partly cloudy", boosts: []string{"normal", "rock"}},
+}
+
+// currentWeather is rolled once at startup from rng.
+var currentWeather weather
+
+func pickWeather(r *rand.Rand) weather {
+	return weathers[r.Intn(len(weathers))]
+}
+
+// Boosts reports whether w favors one of pokemon's types.
+func (w weather) Boosts(pokemon Pokemon) bool {
+	for _, t := range pokemon.Types {
+		for _, boosted := range w.boosts {
+			if t.Type.Name == boosted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apply returns ball with its odds improved when w favors pokemon.
+func (w weather) apply(ball pokeball, pokemon Pokemon) pokeball {
+	if w.Boosts(pokemon) {
+		ball.multiplier *= weatherBoost
+	}
+	return ball
+}
+
+func commandWeather(params ...string) error {
+	fmt.Printf("Current weather: %s (boosts %s)\n", currentWeather.name, strings.Join(currentWeather.boosts, ", "))
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestWeatherBoosts(t *testing.T) {
+	sunny := weather{name: "sunny", boosts: []string{"fire", "grass", "ground"}}
+	tests := []struct {
+		types []string
+		want  bool
+	}{
+		{[]string{"fire"}, true},
+		{[]string{"poison", "grass"}, true},
+		{[]string{"water"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		var pokemon Pokemon
+		if err := json.Unmarshal([]byte(pokemonJSON(1, "test", 64, tt.types...)), &pokemon); err != nil {
+			t.Fatal(err)
+		}
+		if got := sunny.Boosts(pokemon); got != tt.want {
+			t.Errorf("sunny.Boosts(%v) = %v, want %v", tt.types, got, tt.want)
+		}
+
+		ball := sunny.apply(pokeballs["great"], pokemon)
+		want := pokeballs["great"].multiplier
+		if tt.want {
+			want *= weatherBoost
+		}
+		if ball.multiplier != want {
+			t.Errorf("sunny.apply(great, %v) multiplier = %v, want %v", tt.types, ball.multiplier, want)
+		}
+	}
+}
+
+func TestWeatherBoostsEachTypeOnce(t *testing.T) {
+	seen := make(map[string]string)
+	for _, w := range weathers {
+		for _, boosted := range w.boosts {
+			if other, ok := seen[boosted]; ok {
+				t.Errorf("%s is boosted by both %s and %s", boosted, other, w.name)
+			}
+			seen[boosted] = w.name
+		}
+	}
+}
+
+func TestPickWeatherIsSeeded(t *testing.T) {
+	first := pickWeather(rand.New(rand.NewSource(7)))
+	if again := pickWeather(rand.New(rand.NewSource(7))); again.name != first.name {
+		t.Errorf("the same seed picked %s then %s", first.name, again.name)
+	}
+}
+
+func TestCommandWeather(t *testing.T) {
+	setupTest(t)
+	currentWeather = weather{name: "rain", boosts: []string{"water", "electric", "bug"}}
+	out := captureOutput(t, func() {
+		if err := commandWeather(); err != nil {
+			t.Errorf("weather: %v", err)
+		}
+	})
+	if want := "Current weather: rain (boosts water, electric, bug)\n"; out != want {
+		t.Errorf("weather printed %q, want %q", out, want)
+	}
+}
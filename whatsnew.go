@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func defaultLocationsSnapshotPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".pokedex_locations")
+}
+
+// readNameList reads one name per line, as written by mapall. Blank lines
+// are skipped.
+func readNameList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			names = append(names, line)
+		}
+	}
+	return names, nil
+}
+
+func writeNameList(path string, names []string) error {
+	return os.WriteFile(path, []byte(strings.Join(names, "\n")+"\n"), 0644)
+}
+
+// commandWhatsNew compares the current locations with a snapshot saved by an
+// earlier run (or by mapall) and then updates the snapshot.
+func commandWhatsNew(params ...string) error {
+	path := defaultLocationsSnapshotPath()
+	if len(params) > 0 {
+		path = params[0]
+	}
+	if path == "" {
+		fmt.Println("Please provide a snapshot file")
+		return errors.New("no snapshot file")
+	}
+	current, err := locationNames()
+	if err != nil {
+		return err
+	}
+	saved, err := readNameList(path)
+	if os.IsNotExist(err) {
+		fmt.Printf("No snapshot yet, saved %d locations to %s\n", len(current), path)
+		return writeNameList(path, current)
+	}
+	if err != nil {
+		fmt.Println("Error reading snapshot:", err)
+		return err
+	}
+
+	added, removed, _ := diffSets(current, saved)
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Println("No changes since the last snapshot")
+		return nil
+	}
+	printNameGroup("Added", added)
+	printNameGroup("Removed", removed)
+	return writeNameList(path, current)
+}
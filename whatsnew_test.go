@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadNameList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "locations")
+	if err := os.WriteFile(path, []byte("canalave-city-area\n\n  eterna-city-area  \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	names, err := readNameList(path)
+	if want := []string{"canalave-city-area", "eterna-city-area"}; err != nil || !reflect.DeepEqual(names, want) {
+		t.Errorf("readNameList = %v, %v, want %v", names, err, want)
+	}
+}
+
+func TestWhatsNew(t *testing.T) {
+	setupTest(t)
+	stubLocationPages(t)
+	path := filepath.Join(t.TempDir(), "locations")
+
+	out := captureOutput(t, func() {
+		if err := commandWhatsNew(path); err != nil {
+			t.Errorf("whatsnew: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No snapshot yet, saved 5 locations") {
+		t.Errorf("first whatsnew printed:\n%s", out)
+	}
+	out = captureOutput(t, func() {
+		if err := commandWhatsNew(path); err != nil {
+			t.Errorf("whatsnew: %v", err)
+		}
+	})
+	if out != "No changes since the last snapshot\n" {
+		t.Errorf("whatsnew without changes printed:\n%s", out)
+	}
+
+	if err := writeNameList(path, []string{"canalave-city-area", "eterna-city-area", "old-chateau"}); err != nil {
+		t.Fatal(err)
+	}
+	out = captureOutput(t, func() {
+		if err := commandWhatsNew(path); err != nil {
+			t.Errorf("whatsnew: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Added (3):\n  eterna-forest-area, mt-coronet-1f, pastoria-city-area\n",
+		"Removed (1):\n  old-chateau\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("whatsnew output lacks %q:\n%s", want, out)
+		}
+	}
+	names, err := readNameList(path)
+	if err != nil || len(names) != 5 {
+		t.Errorf("snapshot holds %v, %v after whatsnew, want the 5 current locations", names, err)
+	}
+}